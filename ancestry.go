@@ -0,0 +1,104 @@
+package dpp
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+)
+
+// The binary ancestry format is a version byte followed by flagged fields, transactions and
+// merkle proofs are length prefixed, mAPI responses are a count followed by length prefixed responses.
+const (
+	ancestryVersion   byte = 0x01
+	ancestryFlagTx    byte = 0x01
+	ancestryFlagProof byte = 0x02
+	ancestryFlagMAPI  byte = 0x03
+)
+
+// ErrUTXONotFound is returned when the output spent by an input cannot be found.
+var ErrUTXONotFound = errors.New("utxo not found")
+
+// Ancestry are the transactions sent in a Payment ancestry keyed by txid, it is a UTXOReader
+// returning the outputs of those transactions. Proofs and mAPI responses are not read.
+type Ancestry map[string]*bt.Tx
+
+// NewAncestryFromHex will decode a hex encoded binary ancestry.
+func NewAncestryFromHex(s string) (Ancestry, error) {
+	bb, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid ancestry hex")
+	}
+	if len(bb) == 0 || bb[0] != ancestryVersion {
+		return nil, errors.New("unsupported ancestry version")
+	}
+	r := bytes.NewReader(bb[1:])
+	a := Ancestry{}
+	for r.Len() > 0 {
+		flag, _ := r.ReadByte()
+		switch flag {
+		case ancestryFlagTx:
+			b, err := readAncestryField(r)
+			if err != nil {
+				return nil, err
+			}
+			tx, err := bt.NewTxFromBytes(b)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid ancestry transaction")
+			}
+			a[tx.TxID()] = tx
+		case ancestryFlagProof:
+			if _, err := readAncestryField(r); err != nil {
+				return nil, err
+			}
+		case ancestryFlagMAPI:
+			var n bt.VarInt
+			if _, err := n.ReadFrom(r); err != nil {
+				return nil, errors.Wrap(err, "ancestry is truncated")
+			}
+			for i := uint64(0); i < uint64(n); i++ {
+				if _, err := readAncestryField(r); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown ancestry flag %x", flag)
+		}
+	}
+	return a, nil
+}
+
+// readAncestryField reads a length prefixed field.
+func readAncestryField(r *bytes.Reader) ([]byte, error) {
+	var l bt.VarInt
+	if _, err := l.ReadFrom(r); err != nil {
+		return nil, errors.Wrap(err, "ancestry is truncated")
+	}
+	if uint64(l) > uint64(r.Len()) {
+		return nil, errors.New("ancestry is truncated")
+	}
+	b := make([]byte, l)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errors.Wrap(err, "ancestry is truncated")
+	}
+	return b, nil
+}
+
+// UTXO will return the output of an ancestry transaction or ErrUTXONotFound.
+func (a Ancestry) UTXO(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+	tx, ok := a[txID]
+	if !ok || int(vout) >= len(tx.Outputs) {
+		return nil, errors.Wrapf(ErrUTXONotFound, "%s:%d is not in the ancestry", txID, vout)
+	}
+	o := tx.Outputs[vout]
+	return &bt.UTXO{
+		TxID:          tx.TxIDBytes(),
+		Vout:          vout,
+		LockingScript: o.LockingScript,
+		Satoshis:      o.Satoshis,
+	}, nil
+}
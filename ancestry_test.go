@@ -0,0 +1,75 @@
+package dpp_test
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestNewAncestryFromHex(t *testing.T) {
+	script, err := bscript.NewFromHexString(p2pkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := bt.NewTx()
+	if err := parent.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", 0, p2pkh, 2000); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.PayTo(script, 1000); err != nil {
+		t.Fatal(err)
+	}
+	field := func(flag byte, bb []byte) []byte {
+		return append(append([]byte{flag}, bt.VarInt(len(bb)).Bytes()...), bb...)
+	}
+	valid := []byte{0x01}
+	valid = append(valid, field(0x01, parent.Bytes())...)
+	valid = append(valid, field(0x02, []byte{0xab, 0xcd})...)
+	valid = append(valid, 0x03, 0x01)
+	valid = append(valid, bt.VarInt(2).Bytes()...)
+	valid = append(valid, 0x7b, 0x7d)
+
+	tests := map[string]struct {
+		ancestry string
+		err      bool
+	}{
+		"transactions, proofs and mapi responses should be read": {
+			ancestry: hex.EncodeToString(valid),
+		}, "unknown version should error": {
+			ancestry: "02" + hex.EncodeToString(valid[1:]),
+			err:      true,
+		}, "truncated ancestry should error": {
+			ancestry: hex.EncodeToString(valid[:20]),
+			err:      true,
+		}, "unknown flag should error": {
+			ancestry: "0109",
+			err:      true,
+		}, "invalid hex should error": {
+			ancestry: "zz",
+			err:      true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			a, err := dpp.NewAncestryFromHex(test.ancestry)
+			if test.err {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			utxo, err := a.UTXO(context.Background(), parent.TxID(), 0)
+			is.NoErr(err)
+			is.Equal(utxo.Satoshis, uint64(1000))
+			is.Equal(utxo.TxIDStr(), parent.TxID())
+			_, err = a.UTXO(context.Background(), parent.TxID(), 1)
+			is.True(errors.Is(err, dpp.ErrUTXONotFound))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package dpp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AuditEventType identifies what an audit entry recorded.
+type AuditEventType string
+
+// Supported audit event types.
+const (
+	// AuditEventPaymentRequest records a PaymentRequest served to a payer.
+	AuditEventPaymentRequest AuditEventType = "payment_request"
+	// AuditEventPayment records a Payment received from a payer.
+	AuditEventPayment AuditEventType = "payment"
+	// AuditEventPaymentACK records the PaymentACK returned to a payer.
+	AuditEventPaymentACK AuditEventType = "payment_ack"
+	// AuditEventPaymentError records a Payment that failed with an error rather than an ACK.
+	AuditEventPaymentError AuditEventType = "payment_error"
+	// AuditEventProof records a merkle proof envelope received.
+	AuditEventProof AuditEventType = "proof"
+)
+
+// AuditGenesisHash is the PrevHash of the first entry in an audit log.
+var AuditGenesisHash = strings.Repeat("0", sha256.Size*2)
+
+// AuditRecord is an event to be appended to the audit log.
+type AuditRecord struct {
+	Type      AuditEventType
+	PaymentID string
+	TxID      string
+	// Payload is the message recorded, ie the Payment body exactly as sent by the payer.
+	Payload []byte
+}
+
+// AuditEntry is an event stored in the audit log, each entry is chained to the
+// one before it by including its hash so edits, removals and gaps can be detected.
+type AuditEntry struct {
+	// Seq is the position of the entry in the log, starting at 1.
+	Seq       uint64         `json:"seq"`
+	Type      AuditEventType `json:"type"`
+	PaymentID string         `json:"paymentId,omitempty"`
+	TxID      string         `json:"txid,omitempty"`
+	// Payload is base64 encoded in JSON so the recorded bytes, and the hash, survive a round trip.
+	Payload   []byte    `json:"payload" swaggertype:"primitive,string" format:"base64"`
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	// PrevHash is the Hash of the previous entry or AuditGenesisHash for the first entry.
+	PrevHash string `json:"prevHash"`
+	// Hash is the hex encoded sha256 of the entry, see ComputeHash.
+	Hash string `json:"hash"`
+}
+
+// NewAuditEntry returns the entry that follows prev for the record, prev is nil
+// for the first entry in a log. Stores should call this while holding the log.
+func NewAuditEntry(prev *AuditEntry, rec AuditRecord, at time.Time) AuditEntry {
+	e := AuditEntry{
+		Seq:       1,
+		Type:      rec.Type,
+		PaymentID: rec.PaymentID,
+		TxID:      rec.TxID,
+		Payload:   append([]byte{}, rec.Payload...),
+		CreatedAt: at.UTC(),
+		PrevHash:  AuditGenesisHash,
+	}
+	if prev != nil {
+		e.Seq = prev.Seq + 1
+		e.PrevHash = prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+	return e
+}
+
+// ComputeHash returns the hash of every field of the entry other than Hash. Each field
+// is prefixed with its length so no two different entries hash the same bytes.
+func (a AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	var seq [8]byte
+	binary.BigEndian.PutUint64(seq[:], a.Seq)
+	for _, f := range [][]byte{
+		seq[:],
+		[]byte(a.Type),
+		[]byte(a.PaymentID),
+		[]byte(a.TxID),
+		[]byte(a.CreatedAt.UTC().Format(time.RFC3339Nano)),
+		[]byte(a.PrevHash),
+		a.Payload,
+	} {
+		writeAuditField(h, f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeAuditField writes the length of f followed by f.
+func writeAuditField(h hash.Hash, f []byte) {
+	var n [8]byte
+	binary.BigEndian.PutUint64(n[:], uint64(len(f)))
+	_, _ = h.Write(n[:])
+	_, _ = h.Write(f)
+}
+
+// AuditPayload encodes v as an audit payload.
+func AuditPayload(v interface{}) ([]byte, error) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode audit payload")
+	}
+	return bb, nil
+}
+
+// AuditVerifyError is returned when an audit log has been tampered with.
+type AuditVerifyError struct {
+	// Seq is the first entry found to be invalid.
+	Seq    uint64
+	Reason string
+}
+
+// Error satisfies the error interface.
+func (e AuditVerifyError) Error() string {
+	return fmt.Sprintf("audit entry %d is invalid: %s", e.Seq, e.Reason)
+}
+
+// VerifyAuditLog checks a complete audit log read oldest first, it returns an
+// AuditVerifyError at the first entry that is missing, out of order, edited or
+// not chained to the previous entry.
+func VerifyAuditLog(entries []AuditEntry) error {
+	prevHash := AuditGenesisHash
+	for i, e := range entries {
+		seq := uint64(i + 1)
+		switch {
+		case e.Seq != seq:
+			return AuditVerifyError{Seq: seq, Reason: fmt.Sprintf("found entry %d, entries are missing or out of order", e.Seq)}
+		case e.PrevHash != prevHash:
+			return AuditVerifyError{Seq: seq, Reason: "prevHash does not match the previous entry"}
+		case e.Hash != e.ComputeHash():
+			return AuditVerifyError{Seq: seq, Reason: "hash does not match the entry contents"}
+		}
+		prevHash = e.Hash
+	}
+	return nil
+}
+
+// AuditArgs are used to filter audit entries.
+type AuditArgs struct {
+	// PaymentID returns entries for this invoice, the result can no longer be verified
+	// with VerifyAuditLog as other entries in the chain are excluded.
+	PaymentID string `query:"paymentId"`
+}
+
+// AuditWriter appends to the audit log.
+type AuditWriter interface {
+	// AuditAppend will atomically append the record after the last entry using NewAuditEntry.
+	AuditAppend(ctx context.Context, req AuditRecord) (*AuditEntry, error)
+}
+
+// AuditReader reads the audit log.
+type AuditReader interface {
+	// AuditEntries returns the entries matching the args, oldest first.
+	AuditEntries(ctx context.Context, args AuditArgs) ([]AuditEntry, error)
+}
+
+// AuditReaderWriter combines the reader and writer interfaces.
+type AuditReaderWriter interface {
+	AuditReader
+	AuditWriter
+}
+
+type rawBodyKey struct{}
+
+// WithRawBody returns a context carrying the request body exactly as it was received, audit
+// decorators record it in place of the re-encoded request so the payers bytes are kept.
+func WithRawBody(ctx context.Context, body []byte) context.Context {
+	return context.WithValue(ctx, rawBodyKey{}, body)
+}
+
+// RawBody returns the request body added with WithRawBody.
+func RawBody(ctx context.Context) ([]byte, bool) {
+	body, ok := ctx.Value(rawBodyKey{}).([]byte)
+	return body, ok
+}
@@ -0,0 +1,110 @@
+package dpp_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+func auditLog(n int) []dpp.AuditEntry {
+	ee := make([]dpp.AuditEntry, 0, n)
+	var prev *dpp.AuditEntry
+	for i := 0; i < n; i++ {
+		e := dpp.NewAuditEntry(prev, dpp.AuditRecord{
+			Type:      dpp.AuditEventPayment,
+			PaymentID: "abc123",
+			Payload:   []byte(`{"memo": "a<b"}`),
+		}, time.Now())
+		ee = append(ee, e)
+		prev = &ee[len(ee)-1]
+	}
+	return ee
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	tests := map[string]struct {
+		log    func() []dpp.AuditEntry
+		expSeq uint64
+	}{
+		"untouched log should verify": {
+			log: func() []dpp.AuditEntry { return auditLog(4) },
+		}, "empty log should verify": {
+			log: func() []dpp.AuditEntry { return nil },
+		}, "edited payload should be detected": {
+			log: func() []dpp.AuditEntry {
+				ee := auditLog(4)
+				ee[2].Payload = []byte(`{"memo":"bye"}`)
+				return ee
+			},
+			expSeq: 3,
+		}, "edit with recomputed hash should break the chain": {
+			log: func() []dpp.AuditEntry {
+				ee := auditLog(4)
+				ee[1].Payload = []byte(`{"memo":"bye"}`)
+				ee[1].Hash = ee[1].ComputeHash()
+				return ee
+			},
+			expSeq: 3,
+		}, "removed entry should be detected": {
+			log: func() []dpp.AuditEntry {
+				ee := auditLog(4)
+				return append(ee[:1], ee[2:]...)
+			},
+			expSeq: 2,
+		}, "removed first entry should be detected": {
+			log: func() []dpp.AuditEntry {
+				return auditLog(4)[1:]
+			},
+			expSeq: 1,
+		}, "reordered entries should be detected": {
+			log: func() []dpp.AuditEntry {
+				ee := auditLog(4)
+				ee[1], ee[2] = ee[2], ee[1]
+				return ee
+			},
+			expSeq: 2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			err := dpp.VerifyAuditLog(test.log())
+			if test.expSeq == 0 {
+				is.NoErr(err)
+				return
+			}
+			var errVerify dpp.AuditVerifyError
+			is.True(errors.As(err, &errVerify))
+			is.Equal(errVerify.Seq, test.expSeq)
+		})
+	}
+}
+
+func TestVerifyAuditLog_JSONRoundTrip(t *testing.T) {
+	is := is.New(t)
+	// the payload is not compacted or escaped when the log is exported and read back.
+	ee := auditLog(3)
+	bb, err := json.Marshal(ee)
+	is.NoErr(err)
+	var read []dpp.AuditEntry
+	is.NoErr(json.Unmarshal(bb, &read))
+	is.Equal(string(read[0].Payload), `{"memo": "a<b"}`)
+	is.NoErr(dpp.VerifyAuditLog(read))
+}
+
+func TestAuditEntry_ComputeHash(t *testing.T) {
+	is := is.New(t)
+	at := time.Now()
+	// moving bytes between fields must change the hash.
+	a := dpp.NewAuditEntry(nil, dpp.AuditRecord{Type: dpp.AuditEventPayment, PaymentID: "abc\n", TxID: "def"}, at)
+	b := dpp.NewAuditEntry(nil, dpp.AuditRecord{Type: dpp.AuditEventPayment, PaymentID: "abc", TxID: "\ndef"}, at)
+	is.True(a.Hash != b.Hash)
+	c := dpp.NewAuditEntry(nil, dpp.AuditRecord{Type: dpp.AuditEventPayment, PaymentID: "abc", Payload: []byte("def")}, at)
+	d := dpp.NewAuditEntry(nil, dpp.AuditRecord{Type: dpp.AuditEventPayment, PaymentID: "abcdef"}, at)
+	is.True(c.Hash != d.Hash)
+}
@@ -0,0 +1,121 @@
+package dpp
+
+import (
+	"encoding/json"
+
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Beneficiary is the merchant being paid, displayed to the user.
+type Beneficiary struct {
+	// AvatarURL displays a canonical url to a merchants avatar.
+	AvatarURL string `json:"avatar" example:"http://url.com"`
+	// Name is a human readable string identifying the merchant.
+	Name string `json:"name" example:"merchant 1"`
+	// Email can be sued to contact the merchant about this transaction.
+	Email string `json:"email" example:"merchant@m.com"`
+	// Address is the merchants store / head office address.
+	Address string `json:"address" example:"1 the street, the town, B1 1AA"`
+	// PaymentReference identifies the invoice being paid, it is echoed back by the payer.
+	PaymentReference string `json:"paymentReference" example:"Order-325214"`
+	// ExtendedData can be supplied if the merchant wishes to send some arbitrary data back to the wallet.
+	ExtendedData map[string]interface{} `json:"extendedData,omitempty"`
+	// AuthTag authenticates the PaymentReference, it is issued with the PaymentRequest
+	// and must be echoed back unchanged in the Payment MerchantData.
+	AuthTag string `json:"authTag,omitempty" example:"5d0a8d5c7b0e4c1c5b7a3f2e1d0c9b8a7f6e5d4c3b2a190807060504030201ff"`
+}
+
+// Merchant is the previous name of Beneficiary.
+//
+// Deprecated: use Beneficiary.
+type Merchant = Beneficiary
+
+// Reference returns the PaymentReference, falling back to the
+// extendedData.paymentReference used before it was typed.
+func (b Beneficiary) Reference() string {
+	if b.PaymentReference != "" {
+		return b.PaymentReference
+	}
+	ref, _ := b.ExtendedData["paymentReference"].(string)
+	return ref
+}
+
+// Validate will ensure the Beneficiary is valid.
+func (b Beneficiary) Validate() error {
+	return b.details().
+		Validate("paymentReference", validator.NotEmpty(b.Reference())).
+		Err()
+}
+
+// validateDetails will ensure the merchant details are valid, the PaymentReference
+// is not checked as it is generated when a PaymentRequest is created.
+func (b Beneficiary) validateDetails() error {
+	return b.details().Err()
+}
+
+func (b Beneficiary) details() validator.ErrValidation {
+	v := validator.New().
+		Validate("name", validator.StrLength(b.Name, 0, 100))
+	if b.Email != "" {
+		v = v.Validate("email", validator.Email(b.Email))
+	}
+	return v
+}
+
+// isZero returns true if no field of the Beneficiary is set.
+func (b Beneficiary) isZero() bool {
+	return b.AvatarURL == "" && b.Name == "" && b.Email == "" && b.Address == "" &&
+		b.PaymentReference == "" && len(b.ExtendedData) == 0 && b.AuthTag == ""
+}
+
+// MarshalJSON will also write the PaymentReference to extendedData.paymentReference
+// so wallets reading the old shape can echo it back.
+func (b Beneficiary) MarshalJSON() ([]byte, error) {
+	type alias Beneficiary
+	a := alias(b)
+	if ref := b.Reference(); ref != "" {
+		a.PaymentReference = ref
+		a.ExtendedData = make(map[string]interface{}, len(b.ExtendedData)+1)
+		for k, v := range b.ExtendedData {
+			a.ExtendedData[k] = v
+		}
+		a.ExtendedData["paymentReference"] = ref
+	}
+	return json.Marshal(a)
+}
+
+// UnmarshalJSON will read the PaymentReference from extendedData.paymentReference
+// when it is sent in the old shape.
+func (b *Beneficiary) UnmarshalJSON(bb []byte) error {
+	type alias Beneficiary
+	var a alias
+	if err := json.Unmarshal(bb, &a); err != nil {
+		return err
+	}
+	*b = Beneficiary(a)
+	b.PaymentReference = b.Reference()
+	return nil
+}
+
+// Originator is the payer, optionally supplied with a Payment.
+type Originator struct {
+	// Name is a human readable string identifying the payer.
+	Name string `json:"name" example:"Satoshi Nakamoto"`
+	// Paymail is the paymail of the payer.
+	Paymail string `json:"paymail" example:"satoshi@bitcoin.com"`
+	// Avatar is a url to the payers avatar.
+	Avatar string `json:"avatar" example:"http://url.com"`
+	// ExtendedData can be supplied if the payer wishes to send arbitrary data to the merchant.
+	ExtendedData map[string]interface{} `json:"extendedData,omitempty"`
+}
+
+// Validate will ensure the Originator is valid.
+func (o Originator) Validate() error {
+	v := validator.New().
+		Validate("name", validator.StrLength(o.Name, 0, 100)).
+		Validate("avatar", validator.StrLength(o.Avatar, 0, 1000))
+	if o.Paymail != "" {
+		v = v.Validate("paymail", ValidatePaymail(o.Paymail))
+	}
+	return v.Err()
+}
@@ -0,0 +1,110 @@
+package dpp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestBeneficiary_JSON(t *testing.T) {
+	tests := map[string]struct {
+		json   string
+		expRef string
+	}{
+		"typed payment reference": {
+			json:   `{"name":"merchant","paymentReference":"abc123"}`,
+			expRef: "abc123",
+		},
+		"old extended data shape": {
+			json:   `{"name":"merchant","extendedData":{"paymentReference":"abc123"}}`,
+			expRef: "abc123",
+		},
+		"typed reference preferred": {
+			json:   `{"paymentReference":"abc123","extendedData":{"paymentReference":"def456"}}`,
+			expRef: "abc123",
+		},
+		"non string extended data reference ignored": {
+			json: `{"extendedData":{"paymentReference":123}}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			var b dpp.Beneficiary
+			is.NoErr(json.Unmarshal([]byte(test.json), &b))
+			is.Equal(b.PaymentReference, test.expRef)
+		})
+	}
+}
+
+func TestBeneficiary_MarshalJSON(t *testing.T) {
+	is := is.New(t)
+	b := dpp.Beneficiary{Name: "merchant", PaymentReference: "abc123"}
+	bb, err := json.Marshal(b)
+	is.NoErr(err)
+	var out map[string]interface{}
+	is.NoErr(json.Unmarshal(bb, &out))
+	is.Equal(out["paymentReference"], "abc123")
+	is.Equal(out["extendedData"], map[string]interface{}{"paymentReference": "abc123"})
+	// the original is not modified.
+	is.Equal(b.ExtendedData, nil)
+}
+
+func TestPaymentRequest_JSON_MerchantData(t *testing.T) {
+	is := is.New(t)
+	var pr dpp.PaymentRequest
+	is.NoErr(json.Unmarshal([]byte(`{"network":"mainnet","merchantData":{"name":"merchant","extendedData":{"paymentReference":"abc123"}}}`), &pr))
+	is.Equal(pr.Beneficiary.Name, "merchant")
+	is.Equal(pr.Beneficiary.PaymentReference, "abc123")
+
+	bb, err := json.Marshal(pr)
+	is.NoErr(err)
+	var out map[string]interface{}
+	is.NoErr(json.Unmarshal(bb, &out))
+	is.Equal(out["beneficiary"], out["merchantData"])
+}
+
+func TestPaymentRequest_Payee_MerchantData(t *testing.T) {
+	is := is.New(t)
+	// Go callers still setting the deprecated field are written in both shapes.
+	pr := dpp.PaymentRequest{MerchantData: &dpp.Merchant{Name: "merchant", PaymentReference: "abc123"}}
+	is.Equal(pr.Payee().Name, "merchant")
+	bb, err := json.Marshal(pr)
+	is.NoErr(err)
+	var out map[string]interface{}
+	is.NoErr(json.Unmarshal(bb, &out))
+	is.True(out["beneficiary"] != nil)
+	is.Equal(out["beneficiary"], out["merchantData"])
+
+	pr.Beneficiary = &dpp.Beneficiary{Name: "beneficiary"}
+	is.Equal(pr.Payee().Name, "beneficiary")
+
+	prc := dpp.PaymentRequestCreate{MerchantData: dpp.Merchant{Name: "merchant"}}
+	is.Equal(prc.Payee().Name, "merchant")
+	prc.Beneficiary = dpp.Beneficiary{Name: "beneficiary"}
+	is.Equal(prc.Payee().Name, "beneficiary")
+}
+
+func TestPaymentRequestCreate_Validate_Beneficiary(t *testing.T) {
+	is := is.New(t)
+	req := dpp.PaymentRequestCreate{Amounts: []uint64{1000}, Beneficiary: dpp.Beneficiary{Name: "merchant", Email: "merchant@m.com"}}
+	is.NoErr(req.Validate())
+	req.Beneficiary.Email = "not an email"
+	is.Equal(req.Validate().Error(), "[beneficiary: [email: invalid email]]")
+}
+
+func TestPayment_Validate_Originator(t *testing.T) {
+	is := is.New(t)
+	rawTx := "0100000000010000000000000000066a04deadbeef00000000"
+	p := dpp.Payment{
+		RawTx:        &rawTx,
+		MerchantData: dpp.Beneficiary{PaymentReference: "abc123"},
+		Originator:   &dpp.Originator{Name: "payer", Paymail: "payer@paymail.com"},
+	}
+	is.NoErr(p.Validate())
+	p.Originator.Paymail = "not a paymail"
+	is.Equal(p.Validate().Error(), "[originator: [paymail: value must be a valid paymail in the format alias@domain.tld]]")
+}
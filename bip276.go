@@ -0,0 +1,122 @@
+package dpp
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+)
+
+// bip276Regex matches prefix:<network><version><data><checksum> with the numbers and checksum hex encoded.
+var bip276Regex = regexp.MustCompile(`^(.+?):([0-9A-Fa-f]{2})([0-9A-Fa-f]{2})([0-9A-Fa-f]*)([0-9A-Fa-f]{8})$`)
+
+// ParseBIP276 will decode a BIP276 string and ensure it is a script at the current version.
+// Templates are rejected, they describe a script to be completed by the payer so cannot be paid.
+//
+// bscript.DecodeBIP276 reads the network and version in the wrong order so fails the
+// checksum for anything but mainnet, the string is decoded here to match the BIP276
+// spec and bscript.EncodeBIP276.
+func ParseBIP276(s string) (*bscript.BIP276, error) {
+	res := bip276Regex.FindStringSubmatch(s)
+	if len(res) == 0 {
+		return nil, bscript.ErrTextNoBIP76
+	}
+	payload := s[:len(s)-8]
+	if hex.EncodeToString(crypto.Sha256d([]byte(payload))[:4]) != strings.ToLower(res[5]) {
+		return nil, bscript.ErrEncodingInvalidChecksum
+	}
+	network, _ := strconv.ParseUint(res[2], 16, 8)
+	version, _ := strconv.ParseUint(res[3], 16, 8)
+	data, err := hex.DecodeString(res[4])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid bip276 data")
+	}
+	b := &bscript.BIP276{
+		Prefix:  res[1],
+		Network: int(network),
+		Version: int(version),
+		Data:    data,
+	}
+	switch b.Prefix {
+	case bscript.PrefixScript:
+	case bscript.PrefixTemplate:
+		return nil, errors.New("bip276 templates are not supported, a script is required")
+	default:
+		return nil, fmt.Errorf("unknown bip276 prefix %s", b.Prefix)
+	}
+	if b.Version != bscript.CurrentVersion {
+		return nil, fmt.Errorf("unsupported bip276 version %d", b.Version)
+	}
+	if len(b.Data) == 0 {
+		return nil, errors.New("bip276 script is empty")
+	}
+	return b, nil
+}
+
+// BIP276Network returns the BIP276 network number for a PaymentRequest network.
+func BIP276Network(network string) (int, error) {
+	switch network {
+	case "mainnet", "bitcoin", "bitcoin-sv":
+		return bscript.NetworkMainnet, nil
+	case "testnet", "test", "stn", "regtest":
+		return bscript.NetworkTestnet, nil
+	}
+	return 0, fmt.Errorf("unknown network %s", network)
+}
+
+// DecodeBIP276 will, if the output has a BIP276 string, check it is valid for the
+// network and set the LockingScript from it. If a LockingScript is already set it
+// must match the decoded script.
+func (o *Output) DecodeBIP276(network string) error {
+	if o.BIP276 == "" {
+		return nil
+	}
+	b, err := ParseBIP276(o.BIP276)
+	if err != nil {
+		return err
+	}
+	n, err := BIP276Network(network)
+	if err != nil {
+		return err
+	}
+	if b.Network != n {
+		return fmt.Errorf("bip276 network %d does not match %s", b.Network, network)
+	}
+	script := bscript.NewFromBytes(b.Data)
+	if o.LockingScript != nil && len(*o.LockingScript) > 0 && !o.LockingScript.Equals(script) {
+		return errors.New("bip276 script does not match locking script")
+	}
+	o.LockingScript = script
+	return nil
+}
+
+// decodeOutputs will decode the BIP276 strings of each output for the network.
+func decodeOutputs(network string, outputs []Output) error {
+	for i := range outputs {
+		if err := outputs[i].DecodeBIP276(network); err != nil {
+			return errors.Wrapf(err, "invalid outputs[%d]", i)
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON will decode the Destinations, setting the locking script of
+// any outputs supplied as BIP276 strings and rejecting unknown or mismatched ones.
+func (d *Destinations) UnmarshalJSON(bb []byte) error {
+	type alias Destinations
+	var dd alias
+	if err := json.Unmarshal(bb, &dd); err != nil {
+		return err
+	}
+	if err := decodeOutputs(dd.Network, dd.Outputs); err != nil {
+		return err
+	}
+	*d = Destinations(dd)
+	return nil
+}
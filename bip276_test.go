@@ -0,0 +1,118 @@
+package dpp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+const p2pkh = "76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"
+
+func encodeBIP276(t *testing.T, prefix string, network, version int) string {
+	s, err := bscript.NewFromHexString(p2pkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bscript.EncodeBIP276(bscript.BIP276{Prefix: prefix, Network: network, Version: version, Data: *s})
+}
+
+func TestOutput_DecodeBIP276(t *testing.T) {
+	tests := map[string]struct {
+		bip276  string
+		network string
+		script  string
+		err     bool
+	}{
+		"mainnet script": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkMainnet, bscript.CurrentVersion),
+			network: "mainnet",
+		},
+		"testnet script": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkTestnet, bscript.CurrentVersion),
+			network: "testnet",
+		},
+		"template": {
+			bip276:  encodeBIP276(t, bscript.PrefixTemplate, bscript.NetworkMainnet, bscript.CurrentVersion),
+			network: "mainnet",
+			err:     true,
+		},
+		"matching locking script": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkMainnet, bscript.CurrentVersion),
+			network: "mainnet",
+			script:  p2pkh,
+		},
+		"mismatched locking script": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkMainnet, bscript.CurrentVersion),
+			network: "mainnet",
+			script:  "006a",
+			err:     true,
+		},
+		"network mismatch": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkTestnet, bscript.CurrentVersion),
+			network: "mainnet",
+			err:     true,
+		},
+		"unknown prefix": {
+			bip276:  encodeBIP276(t, "bitcoin-unknown", bscript.NetworkMainnet, bscript.CurrentVersion),
+			network: "mainnet",
+			err:     true,
+		},
+		"unknown version": {
+			bip276:  encodeBIP276(t, bscript.PrefixScript, bscript.NetworkMainnet, 2),
+			network: "mainnet",
+			err:     true,
+		},
+		"invalid checksum": {
+			bip276:  "bitcoin-script:010176a91455b61be43392125d127f1780fb038437cd67ef9c88ac00000000",
+			network: "mainnet",
+			err:     true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			o := dpp.Output{Amount: 1000, BIP276: test.bip276}
+			if test.script != "" {
+				s, err := bscript.NewFromHexString(test.script)
+				is.NoErr(err)
+				o.LockingScript = s
+			}
+			err := o.DecodeBIP276(test.network)
+			if test.err {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(o.LockingScript.String(), p2pkh)
+		})
+	}
+}
+
+func TestPaymentRequest_UnmarshalJSON(t *testing.T) {
+	is := is.New(t)
+	bip276 := encodeBIP276(t, bscript.PrefixScript, bscript.NetworkTestnet, bscript.CurrentVersion)
+	bb, err := json.Marshal(map[string]interface{}{
+		"network": "testnet",
+		"destinations": map[string]interface{}{
+			"outputs": []map[string]interface{}{{"amount": 1000, "bip276": bip276}},
+		},
+	})
+	is.NoErr(err)
+	var pr dpp.PaymentRequest
+	is.NoErr(json.Unmarshal(bb, &pr))
+	is.Equal(pr.Destinations.Outputs[0].BIP276, bip276)
+	is.Equal(pr.Destinations.Outputs[0].LockingScript.String(), p2pkh)
+
+	bb, err = json.Marshal(map[string]interface{}{
+		"network": "mainnet",
+		"destinations": map[string]interface{}{
+			"outputs": []map[string]interface{}{{"amount": 1000, "bip276": bip276}},
+		},
+	})
+	is.NoErr(err)
+	is.True(json.Unmarshal(bb, &pr) != nil)
+}
@@ -0,0 +1,192 @@
+// Package builder is used by wallets to create a Payment satisfying a PaymentRequest.
+//
+// The builder adds the requested outputs to a new transaction, funds it from the
+// wallet utxos using a CoinSelector, adds change and signs the inputs before
+// wrapping the transaction in a Payment ready to send to the PaymentURL.
+package builder
+
+import (
+	"context"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+// p2pkhInputBytes is the size of a signed P2PKH input, only P2PKH utxos are supported.
+const p2pkhInputBytes = 148
+
+// changeOutputBytes is the size of a P2PKH change output.
+const changeOutputBytes = 34
+
+// AncestryFunc returns the encoded ancestry of the funding transactions for a tx.
+type AncestryFunc func(ctx context.Context, tx *bt.Tx) (string, error)
+
+// Args contain the payer supplied details for a Payment.
+type Args struct {
+	// UTXOs are the wallet utxos available to fund the payment.
+	UTXOs bt.UTXOs
+	// Memo is an optional note to send to the merchant.
+	Memo string
+	// RefundTo is an optional paymail to refund to.
+	RefundTo *string
+}
+
+// Builder creates Payments from PaymentRequests.
+type Builder struct {
+	ug       bt.UnlockerGetter
+	change   dpp.LockingScriptFunc
+	selector CoinSelector
+	ancestry AncestryFunc
+}
+
+// Opt can be supplied to New to change the behaviour of the Builder.
+type Opt func(b *Builder)
+
+// WithCoinSelector sets the CoinSelector used to fund payments, defaults to largest first.
+func WithCoinSelector(cs CoinSelector) Opt {
+	return func(b *Builder) {
+		b.selector = cs
+	}
+}
+
+// WithAncestry sets the func used to build the ancestry when a PaymentRequest requires one.
+func WithAncestry(fn AncestryFunc) Opt {
+	return func(b *Builder) {
+		b.ancestry = fn
+	}
+}
+
+// New will setup and return a new Builder. The UnlockerGetter is used to sign the
+// inputs and change is called to get a locking script when change is required.
+func New(ug bt.UnlockerGetter, change dpp.LockingScriptFunc, opts ...Opt) *Builder {
+	b := &Builder{
+		ug:       ug,
+		change:   change,
+		selector: NewLargestFirst(),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// BuildSigned will check the PaymentRequest envelope served by the payment host was signed by the
+// merchant key before building a Payment for it. The key should be pinned or obtained from a
+// trusted source, not from the envelope or the payment host serving it.
+func (b *Builder) BuildSigned(ctx context.Context, env *envelope.JSONEnvelope, merchantKey *bec.PublicKey, args Args) (*dpp.Payment, error) {
+	pr, err := dpp.VerifyPaymentRequest(env, merchantKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to verify payment request")
+	}
+	return b.Build(ctx, *pr, args)
+}
+
+// Build will create and sign a transaction paying the PaymentRequest and return it as a Payment.
+func (b *Builder) Build(ctx context.Context, pr dpp.PaymentRequest, args Args) (*dpp.Payment, error) {
+	if len(pr.Destinations.Outputs) == 0 {
+		return nil, errors.New("payment request has no destinations")
+	}
+	if pr.Payee() == nil {
+		return nil, errors.New("payment request has no beneficiary")
+	}
+	if pr.AncestryRequired && b.ancestry == nil {
+		return nil, errors.New("payment request requires ancestry but no ancestry func is configured")
+	}
+	fq := pr.FeeRate
+	if fq == nil {
+		fq = bt.NewFeeQuote()
+	}
+	stdFee, err := fq.Fee(bt.FeeTypeStandard)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read standard fee")
+	}
+	dataFee, err := fq.Fee(bt.FeeTypeData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read data fee")
+	}
+	if stdFee.MiningFee.Bytes <= 0 || dataFee.MiningFee.Bytes <= 0 {
+		return nil, errors.New("payment request fee rate is invalid")
+	}
+
+	tx := bt.NewTx()
+	var total uint64
+	for _, o := range pr.Destinations.Outputs {
+		tx.AddOutput(&bt.Output{Satoshis: o.Amount, LockingScript: o.LockingScript})
+		total += o.Amount
+	}
+	for i, d := range pr.Destinations.Data {
+		script, err := d.LockingScript()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid data output %d", i)
+		}
+		tx.AddOutput(&bt.Output{LockingScript: script})
+	}
+	size := tx.SizeWithTypes()
+	utxos, err := b.selector.Select(args.UTXOs, SelectionTarget{
+		Satoshis:  total + fee(size.TotalStdBytes, stdFee) + fee(size.TotalDataBytes, dataFee),
+		InputFee:  fee(p2pkhInputBytes, stdFee),
+		ChangeFee: fee(changeOutputBytes, stdFee) + bt.DustLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.FromUTXOs(utxos...); err != nil {
+		return nil, errors.Wrap(err, "failed to add inputs")
+	}
+	changeScript, err := b.change(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get change locking script")
+	}
+	if err := tx.Change(changeScript, fq); err != nil {
+		return nil, errors.Wrap(err, "failed to add change")
+	}
+	if err := tx.FillAllInputs(ctx, b.ug); err != nil {
+		return nil, errors.Wrap(err, "failed to sign inputs")
+	}
+	if err := pr.ValidateFees(tx); err != nil {
+		var errV validator.ErrValidation
+		if errors.As(err, &errV) {
+			return nil, ErrInsufficientFunds
+		}
+		return nil, err
+	}
+
+	rawTx := tx.String()
+	p := &dpp.Payment{
+		MerchantData: *pr.Payee(),
+		RefundTo:     args.RefundTo,
+		Memo:         args.Memo,
+		RawTx:        &rawTx,
+	}
+	if pr.AncestryRequired {
+		ancestry, err := b.ancestry(ctx, tx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build ancestry")
+		}
+		p.Ancestry = &ancestry
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	pubKey, err := pr.EncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	if pubKey != nil {
+		if err := p.Encrypt(pubKey); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// fee returns the fee for a number of bytes rounded up.
+func fee(bytes uint64, f *bt.Fee) uint64 {
+	sats, per := uint64(f.MiningFee.Satoshis), uint64(f.MiningFee.Bytes)
+	return (bytes*sats + per - 1) / per
+}
@@ -0,0 +1,156 @@
+package builder_test
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-bt/v2/unlocker"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/builder"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	walletScript, err := bscript.NewP2PKHFromPubKeyBytes(key.PubKey().SerialiseCompressed())
+	is.NoErr(err)
+	dest, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	txID, err := hex.DecodeString("b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576")
+	is.NoErr(err)
+	funds := bt.UTXOs{
+		{TxID: txID, Vout: 0, LockingScript: walletScript, Satoshis: 500},
+		{TxID: txID, Vout: 1, LockingScript: walletScript, Satoshis: 3000},
+		{TxID: txID, Vout: 2, LockingScript: walletScript, Satoshis: 10000},
+	}
+	pr := dpp.PaymentRequest{
+		Network: "mainnet",
+		Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{
+			{Amount: 1000, LockingScript: dest},
+			{Amount: 1500, LockingScript: dest},
+		}},
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123"},
+		FeeRate:     bt.NewFeeQuote(),
+	}
+	b := builder.New(&unlocker.Getter{PrivateKey: key}, func(ctx context.Context) (*bscript.Script, error) {
+		return walletScript, nil
+	})
+
+	p, err := b.Build(ctx, pr, builder.Args{UTXOs: funds, Memo: "thanks"})
+	is.NoErr(err)
+	is.NoErr(p.Validate())
+	is.Equal(p.Memo, "thanks")
+	is.Equal(p.MerchantData.PaymentReference, "abc123")
+	tx, err := bt.NewTxFromString(*p.RawTx)
+	is.NoErr(err)
+	is.Equal(tx.InputCount(), 1)
+	is.Equal(tx.OutputCount(), 3)
+	is.Equal(tx.Outputs[0].Satoshis, uint64(1000))
+	is.Equal(tx.Outputs[1].Satoshis, uint64(1500))
+	is.True(tx.Outputs[2].LockingScript.Equals(walletScript))
+	for _, in := range tx.Inputs {
+		is.True(in.UnlockingScript != nil && len(*in.UnlockingScript) > 0)
+	}
+	tx.Inputs[0].PreviousTxSatoshis = 10000
+	ok, err := tx.IsFeePaidEnough(pr.FeeRate)
+	is.NoErr(err)
+	is.True(ok)
+
+	_, err = b.Build(ctx, pr, builder.Args{UTXOs: funds[:1]})
+	is.Equal(err, builder.ErrInsufficientFunds)
+
+	pr.AncestryRequired = true
+	_, err = b.Build(ctx, pr, builder.Args{UTXOs: funds})
+	is.True(err != nil)
+
+	b = builder.New(&unlocker.Getter{PrivateKey: key}, func(ctx context.Context) (*bscript.Script, error) {
+		return walletScript, nil
+	}, builder.WithAncestry(func(ctx context.Context, tx *bt.Tx) (string, error) {
+		return "0100beef", nil
+	}), builder.WithCoinSelector(builder.NewSmallestFirst()))
+	p, err = b.Build(ctx, pr, builder.Args{UTXOs: funds})
+	is.NoErr(err)
+	is.Equal(*p.Ancestry, "0100beef")
+	tx, err = bt.NewTxFromString(*p.RawTx)
+	is.NoErr(err)
+	is.Equal(tx.InputCount(), 2)
+}
+
+func TestBuilder_Build_DataOutputs(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	walletScript, err := bscript.NewP2PKHFromPubKeyBytes(key.PubKey().SerialiseCompressed())
+	is.NoErr(err)
+	txID, err := hex.DecodeString("b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576")
+	is.NoErr(err)
+	pr := dpp.PaymentRequest{
+		Destinations: dpp.PaymentDestinations{
+			Outputs: []dpp.Output{{Amount: 1000, LockingScript: walletScript}},
+			Data:    []dpp.DataOutput{{Pushes: []string{"6f72646572", "9f86d081884c7d659a2feaa0c55ad015"}}},
+		},
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123"},
+	}
+	b := builder.New(&unlocker.Getter{PrivateKey: key}, func(ctx context.Context) (*bscript.Script, error) {
+		return walletScript, nil
+	})
+	p, err := b.Build(ctx, pr, builder.Args{UTXOs: bt.UTXOs{
+		{TxID: txID, Vout: 0, LockingScript: walletScript, Satoshis: 5000},
+	}})
+	is.NoErr(err)
+	tx, err := bt.NewTxFromString(*p.RawTx)
+	is.NoErr(err)
+	is.NoErr(pr.ValidateTx(tx))
+	tx.Inputs[0].PreviousTxSatoshis = 5000
+	is.NoErr(pr.ValidateFees(tx))
+}
+
+func TestBuilder_BuildSigned(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	merchantKey, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	walletScript, err := bscript.NewP2PKHFromPubKeyBytes(key.PubKey().SerialiseCompressed())
+	is.NoErr(err)
+	dest, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	txID, err := hex.DecodeString("b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576")
+	is.NoErr(err)
+	funds := bt.UTXOs{{TxID: txID, Vout: 0, LockingScript: walletScript, Satoshis: 10000}}
+	env, err := dpp.NewSignedEnvelope(merchantKey, dpp.PaymentRequest{
+		Network:      "mainnet",
+		Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: dest}}},
+		Beneficiary:  &dpp.Beneficiary{PaymentReference: "abc123"},
+		FeeRate:      bt.NewFeeQuote(),
+	})
+	is.NoErr(err)
+	b := builder.New(&unlocker.Getter{PrivateKey: key}, func(ctx context.Context) (*bscript.Script, error) {
+		return walletScript, nil
+	})
+
+	p, err := b.BuildSigned(ctx, env, merchantKey.PubKey(), builder.Args{UTXOs: funds})
+	is.NoErr(err)
+	is.Equal(p.MerchantData.PaymentReference, "abc123")
+
+	// a request signed by another key is rejected.
+	_, err = b.BuildSigned(ctx, env, key.PubKey(), builder.Args{UTXOs: funds})
+	is.True(err != nil)
+
+	// swapped destinations are rejected.
+	env.Payload = strings.Replace(env.Payload, dest.String(), walletScript.String(), 1)
+	_, err = b.BuildSigned(ctx, env, merchantKey.PubKey(), builder.Args{UTXOs: funds})
+	is.True(errors.Is(err, dpp.ErrInvalidSignature))
+}
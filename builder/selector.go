@@ -0,0 +1,151 @@
+package builder
+
+import (
+	"sort"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+)
+
+// ErrInsufficientFunds is returned when the utxos supplied cannot cover the payment and fees.
+var ErrInsufficientFunds = errors.New("insufficient funds to cover payment and fees")
+
+// bnbMaxTries limits the number of branches explored by the branch and bound selector.
+const bnbMaxTries = 100000
+
+// SelectionTarget is the amount a CoinSelector must fund.
+type SelectionTarget struct {
+	// Satoshis are the output amounts plus the fee for the tx without any inputs.
+	Satoshis uint64
+	// InputFee is the fee to add a single utxo as an input, a utxo is
+	// only worth its satoshis minus this fee.
+	InputFee uint64
+	// ChangeFee is the cost of adding a change output, it is the amount that
+	// may be overpaid to avoid creating change.
+	ChangeFee uint64
+}
+
+// value returns the value of a utxo after paying for it to be spent.
+func (s SelectionTarget) value(u *bt.UTXO) uint64 {
+	if u.Satoshis <= s.InputFee {
+		return 0
+	}
+	return u.Satoshis - s.InputFee
+}
+
+// CoinSelector chooses the utxos used to fund a payment.
+type CoinSelector interface {
+	// Select returns the utxos to spend or ErrInsufficientFunds.
+	Select(utxos bt.UTXOs, target SelectionTarget) (bt.UTXOs, error)
+}
+
+type largestFirst struct{}
+
+// NewLargestFirst returns a CoinSelector that spends the largest utxos first,
+// minimising the number of inputs.
+func NewLargestFirst() CoinSelector {
+	return largestFirst{}
+}
+
+// Select will spend utxos largest first until the target is met.
+func (largestFirst) Select(utxos bt.UTXOs, target SelectionTarget) (bt.UTXOs, error) {
+	return accumulate(sortUTXOs(utxos, target, true), target)
+}
+
+type smallestFirst struct{}
+
+// NewSmallestFirst returns a CoinSelector that spends the smallest utxos first,
+// consolidating dust at the cost of larger transactions.
+func NewSmallestFirst() CoinSelector {
+	return smallestFirst{}
+}
+
+// Select will spend utxos smallest first until the target is met.
+func (smallestFirst) Select(utxos bt.UTXOs, target SelectionTarget) (bt.UTXOs, error) {
+	return accumulate(sortUTXOs(utxos, target, false), target)
+}
+
+type branchAndBound struct {
+	fallback CoinSelector
+}
+
+// NewBranchAndBound returns a CoinSelector that searches for a set of utxos matching
+// the target without needing a change output. If no exact match is found it falls
+// back to largest first.
+func NewBranchAndBound() CoinSelector {
+	return branchAndBound{fallback: NewLargestFirst()}
+}
+
+// Select will search for utxos whose value is between the target and the target plus
+// the cost of a change output.
+func (b branchAndBound) Select(utxos bt.UTXOs, target SelectionTarget) (bt.UTXOs, error) {
+	sorted := sortUTXOs(utxos, target, true)
+	// remaining[i] is the total value of sorted[i:], used to prune branches that cannot reach the target.
+	remaining := make([]uint64, len(sorted)+1)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + target.value(sorted[i])
+	}
+	if remaining[0] < target.Satoshis {
+		return nil, ErrInsufficientFunds
+	}
+	upper := target.Satoshis + target.ChangeFee
+	selected := make([]bool, len(sorted))
+	tries := 0
+	var search func(i int, total uint64) bool
+	search = func(i int, total uint64) bool {
+		tries++
+		switch {
+		case total > upper, tries > bnbMaxTries:
+			return false
+		case total >= target.Satoshis:
+			return true
+		case i == len(sorted), total+remaining[i] < target.Satoshis:
+			return false
+		}
+		selected[i] = true
+		if search(i+1, total+target.value(sorted[i])) {
+			return true
+		}
+		selected[i] = false
+		return search(i+1, total)
+	}
+	if !search(0, 0) {
+		return b.fallback.Select(utxos, target)
+	}
+	var res bt.UTXOs
+	for i, ok := range selected {
+		if ok {
+			res = append(res, sorted[i])
+		}
+	}
+	return res, nil
+}
+
+// sortUTXOs returns the utxos worth spending ordered by value.
+func sortUTXOs(utxos bt.UTXOs, target SelectionTarget, desc bool) bt.UTXOs {
+	sorted := make(bt.UTXOs, 0, len(utxos))
+	for _, u := range utxos {
+		if target.value(u) > 0 {
+			sorted = append(sorted, u)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if desc {
+			return sorted[i].Satoshis > sorted[j].Satoshis
+		}
+		return sorted[i].Satoshis < sorted[j].Satoshis
+	})
+	return sorted
+}
+
+// accumulate selects utxos in order until the target is met.
+func accumulate(utxos bt.UTXOs, target SelectionTarget) (bt.UTXOs, error) {
+	var total uint64
+	for i, u := range utxos {
+		total += target.value(u)
+		if total >= target.Satoshis {
+			return utxos[:i+1], nil
+		}
+	}
+	return nil, ErrInsufficientFunds
+}
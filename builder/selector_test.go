@@ -0,0 +1,97 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp/builder"
+)
+
+func utxos(sats ...uint64) bt.UTXOs {
+	uu := make(bt.UTXOs, 0, len(sats))
+	for i, s := range sats {
+		uu = append(uu, &bt.UTXO{Vout: uint32(i), Satoshis: s})
+	}
+	return uu
+}
+
+func satoshis(uu bt.UTXOs) []uint64 {
+	ss := make([]uint64, 0, len(uu))
+	for _, u := range uu {
+		ss = append(ss, u.Satoshis)
+	}
+	return ss
+}
+
+func TestCoinSelectors(t *testing.T) {
+	tests := map[string]struct {
+		selector builder.CoinSelector
+		utxos    bt.UTXOs
+		target   builder.SelectionTarget
+		exp      []uint64
+		err      error
+	}{
+		"largest first": {
+			selector: builder.NewLargestFirst(),
+			utxos:    utxos(100, 5000, 300, 2000),
+			target:   builder.SelectionTarget{Satoshis: 6000},
+			exp:      []uint64{5000, 2000},
+		},
+		"largest first skips utxos worth less than their input fee": {
+			selector: builder.NewLargestFirst(),
+			utxos:    utxos(10, 10, 1000),
+			target:   builder.SelectionTarget{Satoshis: 1000, InputFee: 10},
+			err:      builder.ErrInsufficientFunds,
+		},
+		"smallest first": {
+			selector: builder.NewSmallestFirst(),
+			utxos:    utxos(100, 5000, 300, 2000),
+			target:   builder.SelectionTarget{Satoshis: 2000},
+			exp:      []uint64{100, 300, 2000},
+		},
+		"smallest first accounts for input fees": {
+			selector: builder.NewSmallestFirst(),
+			utxos:    utxos(100, 5000, 300, 2000),
+			target:   builder.SelectionTarget{Satoshis: 2251, InputFee: 50},
+			exp:      []uint64{100, 300, 2000, 5000},
+		},
+		"branch and bound finds exact match": {
+			selector: builder.NewBranchAndBound(),
+			utxos:    utxos(5000, 3000, 2000, 1500, 700),
+			target:   builder.SelectionTarget{Satoshis: 3700},
+			exp:      []uint64{3000, 700},
+		},
+		"branch and bound match within change cost": {
+			selector: builder.NewBranchAndBound(),
+			utxos:    utxos(5000, 3000, 2000, 1500, 720),
+			target:   builder.SelectionTarget{Satoshis: 3700, ChangeFee: 30},
+			exp:      []uint64{3000, 720},
+		},
+		"branch and bound falls back to largest first": {
+			selector: builder.NewBranchAndBound(),
+			utxos:    utxos(5000, 3000, 2000),
+			target:   builder.SelectionTarget{Satoshis: 3700},
+			exp:      []uint64{5000},
+		},
+		"insufficient funds": {
+			selector: builder.NewBranchAndBound(),
+			utxos:    utxos(100, 200),
+			target:   builder.SelectionTarget{Satoshis: 1000},
+			err:      builder.ErrInsufficientFunds,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			uu, err := test.selector.Select(test.utxos, test.target)
+			if test.err != nil {
+				is.Equal(err, test.err)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(satoshis(uu), test.exp)
+		})
+	}
+}
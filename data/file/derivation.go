@@ -0,0 +1,80 @@
+// Package file contains data stores that persist to the local file system.
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// DerivationIndex stores the next unused derivation index for each key in a JSON file.
+//
+// The file is rewritten atomically on every reservation so an index is never handed
+// out twice, even if the process stops part way through a write.
+type DerivationIndex struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewDerivationIndex will setup and return a new DerivationIndex persisting to the file at path.
+// The file is created on the first reservation if it does not exist.
+func NewDerivationIndex(path string) *DerivationIndex {
+	return &DerivationIndex{path: path}
+}
+
+// DerivationIndexReserve will reserve Count indexes for the key and return the first.
+func (d *DerivationIndex) DerivationIndexReserve(ctx context.Context, args dpp.DerivationIndexArgs) (uint32, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	indexes := map[string]uint32{}
+	bb, err := ioutil.ReadFile(d.path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(bb, &indexes); err != nil {
+			return 0, errors.Wrapf(err, "failed to read derivation indexes from %s", d.path)
+		}
+	case !os.IsNotExist(err):
+		return 0, errors.Wrapf(err, "failed to open %s", d.path)
+	}
+	idx := indexes[args.KeyID]
+	if uint64(idx)+uint64(args.Count) > uint64(dpp.DerivationIndexLimit) {
+		return 0, errors.Wrapf(dpp.ErrDerivationIndexExhausted, "cannot reserve %d indexes for key %s", args.Count, args.KeyID)
+	}
+	indexes[args.KeyID] = idx + args.Count
+	if err := d.write(indexes); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// write replaces the index file by writing to a temporary file and renaming it.
+func (d *DerivationIndex) write(indexes map[string]uint32) error {
+	bb, err := json.Marshal(indexes)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode derivation indexes")
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(d.path), filepath.Base(d.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary index file")
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck // removed on success by rename.
+	if _, err := tmp.Write(bb); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to write derivation indexes")
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return errors.Wrap(err, "failed to sync derivation indexes")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "failed to close derivation indexes")
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), d.path), "failed to replace %s", d.path)
+}
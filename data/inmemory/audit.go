@@ -0,0 +1,35 @@
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-dpp"
+)
+
+// AuditAppend will chain the record to the last audit entry and store it.
+func (s *Store) AuditAppend(ctx context.Context, req dpp.AuditRecord) (*dpp.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var prev *dpp.AuditEntry
+	if n := len(s.audit); n > 0 {
+		prev = &s.audit[n-1]
+	}
+	e := dpp.NewAuditEntry(prev, req, time.Now())
+	s.audit = append(s.audit, e)
+	return &e, nil
+}
+
+// AuditEntries will return the audit entries matching the args, oldest first.
+func (s *Store) AuditEntries(ctx context.Context, args dpp.AuditArgs) ([]dpp.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ee := make([]dpp.AuditEntry, 0, len(s.audit))
+	for _, e := range s.audit {
+		if args.PaymentID != "" && e.PaymentID != args.PaymentID {
+			continue
+		}
+		ee = append(ee, e)
+	}
+	return ee, nil
+}
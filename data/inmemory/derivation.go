@@ -0,0 +1,22 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// DerivationIndexReserve will reserve Count indexes for the key and return the first.
+// Indexes are not persisted so this should only be used in tests.
+func (s *Store) DerivationIndexReserve(ctx context.Context, args dpp.DerivationIndexArgs) (uint32, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	idx := s.derivationIndexes[args.KeyID]
+	if uint64(idx)+uint64(args.Count) > uint64(dpp.DerivationIndexLimit) {
+		return 0, errors.Wrapf(dpp.ErrDerivationIndexExhausted, "cannot reserve %d indexes for key %s", args.Count, args.KeyID)
+	}
+	s.derivationIndexes[args.KeyID] = idx + args.Count
+	return idx, nil
+}
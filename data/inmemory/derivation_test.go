@@ -0,0 +1,33 @@
+package inmemory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+)
+
+func TestStore_DerivationIndexReserve(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	idx, err := store.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{KeyID: "key1", Count: dpp.DerivationIndexLimit - 1})
+	is.NoErr(err)
+	is.Equal(idx, uint32(0))
+	idx, err = store.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{KeyID: "key1", Count: 1})
+	is.NoErr(err)
+	is.Equal(idx, dpp.DerivationIndexLimit-1)
+
+	// the next index would be hardened.
+	_, err = store.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{KeyID: "key1", Count: 1})
+	is.True(errors.Is(err, dpp.ErrDerivationIndexExhausted))
+	// a count overflowing uint32 is rejected rather than wrapping.
+	_, err = store.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{KeyID: "key2", Count: 1})
+	is.NoErr(err)
+	_, err = store.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{KeyID: "key2", Count: ^uint32(0)})
+	is.True(errors.Is(err, dpp.ErrDerivationIndexExhausted))
+}
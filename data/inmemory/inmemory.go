@@ -0,0 +1,46 @@
+// Package inmemory contains a reference data store that keeps all data in memory.
+//
+// It is useful for tests and small deployments but data is lost on restart.
+package inmemory
+
+import (
+	"sync"
+
+	"github.com/libsv/go-bt/v2"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Store is an in memory data store implementing the dpp reader and writer interfaces.
+type Store struct {
+	mu                sync.RWMutex
+	paymentRequests   map[string]dpp.PaymentRequest
+	invoices          map[string]dpp.Invoice
+	payments          []paymentRecord
+	paymentSeq        uint64
+	proofs            []dpp.ProofRecord
+	paymentIndex      map[string]dpp.PaymentIndexEntry
+	spentOutpoints    map[string]string
+	derivationIndexes map[string]uint32
+	proofTokens       map[string]dpp.ProofToken
+	refunds           map[string][]dpp.Refund
+	audit             []dpp.AuditEntry
+	paymentChannels   map[string]dpp.PaymentChannel
+	outbox            []dpp.OutboxMessage
+	utxos             map[string]bt.UTXO
+}
+
+// NewStore will setup and return a new empty in memory data store.
+func NewStore() *Store {
+	return &Store{
+		paymentRequests:   map[string]dpp.PaymentRequest{},
+		invoices:          map[string]dpp.Invoice{},
+		paymentIndex:      map[string]dpp.PaymentIndexEntry{},
+		spentOutpoints:    map[string]string{},
+		derivationIndexes: map[string]uint32{},
+		proofTokens:       map[string]dpp.ProofToken{},
+		refunds:           map[string][]dpp.Refund{},
+		paymentChannels:   map[string]dpp.PaymentChannel{},
+		utxos:             map[string]bt.UTXO{},
+	}
+}
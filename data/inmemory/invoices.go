@@ -0,0 +1,80 @@
+package inmemory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Invoice will return an invoice by its paymentID.
+func (s *Store) Invoice(ctx context.Context, args dpp.InvoiceArgs) (*dpp.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	inv, ok := s.invoices[args.PaymentID]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrInvoiceNotFound)
+	}
+	return copyInvoice(inv), nil
+}
+
+// Invoices will return all invoices created within the args time range, oldest first.
+func (s *Store) Invoices(ctx context.Context, args dpp.InvoicesArgs) ([]dpp.Invoice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	invs := make([]dpp.Invoice, 0, len(s.invoices))
+	for _, inv := range s.invoices {
+		if !args.From.IsZero() && inv.CreatedAt.Before(args.From) {
+			continue
+		}
+		if !args.To.IsZero() && !inv.CreatedAt.Before(args.To) {
+			continue
+		}
+		invs = append(invs, *copyInvoice(inv))
+	}
+	sort.Slice(invs, func(i, j int) bool {
+		if invs[i].CreatedAt.Equal(invs[j].CreatedAt) {
+			return invs[i].ID < invs[j].ID
+		}
+		return invs[i].CreatedAt.Before(invs[j].CreatedAt)
+	})
+	return invs, nil
+}
+
+// InvoiceCreate will store a new invoice, an error is returned if it already exists.
+func (s *Store) InvoiceCreate(ctx context.Context, req dpp.Invoice) (*dpp.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.invoices[req.ID]; ok {
+		return nil, errors.Errorf("invoice %s already exists", req.ID)
+	}
+	s.invoices[req.ID] = *copyInvoice(req)
+	return copyInvoice(req), nil
+}
+
+// InvoiceUpdate will transition the stored invoice to a new state and queue its outbox messages.
+func (s *Store) InvoiceUpdate(ctx context.Context, args dpp.InvoiceArgs, req dpp.InvoiceUpdate) (*dpp.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.invoices[args.PaymentID]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrInvoiceNotFound)
+	}
+	inv := copyInvoice(stored)
+	if err := inv.Transition(req.State, req.Timestamp); err != nil {
+		return nil, err
+	}
+	if req.TxID != "" {
+		inv.TxID = req.TxID
+	}
+	s.invoices[args.PaymentID] = *inv
+	s.outbox = append(s.outbox, req.Outbox...)
+	return copyInvoice(*inv), nil
+}
+
+func copyInvoice(inv dpp.Invoice) *dpp.Invoice {
+	inv.History = append([]dpp.InvoiceStateChange{}, inv.History...)
+	return &inv
+}
@@ -0,0 +1,57 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// OutboxMessage will return an outbox message by id.
+func (s *Store) OutboxMessage(ctx context.Context, args dpp.WebhookArgs) (*dpp.OutboxMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, m := range s.outbox {
+		if m.ID == args.ID {
+			return &m, nil
+		}
+	}
+	return nil, errors.WithStack(dpp.ErrOutboxMessageNotFound)
+}
+
+// OutboxMessages will return the outbox messages matching the args, oldest first.
+func (s *Store) OutboxMessages(ctx context.Context, args dpp.OutboxArgs) ([]dpp.OutboxMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	mm := make([]dpp.OutboxMessage, 0)
+	for _, m := range s.outbox {
+		switch {
+		case args.Status != "" && m.Status != args.Status,
+			!args.DueBy.IsZero() && (m.Status != dpp.WebhookStatusPending || m.NextAttemptAt.After(args.DueBy)):
+			continue
+		}
+		mm = append(mm, m)
+		if args.Limit > 0 && len(mm) == args.Limit {
+			break
+		}
+	}
+	return mm, nil
+}
+
+// OutboxUpdate will replace the stored outbox message if it has not changed since it was read.
+func (s *Store) OutboxUpdate(ctx context.Context, req dpp.OutboxMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.outbox {
+		if s.outbox[i].ID == req.ID {
+			if s.outbox[i].Version != req.Version {
+				return dpp.OutboxConflictError{ID: req.ID}
+			}
+			req.Version++
+			s.outbox[i] = req
+			return nil
+		}
+	}
+	return errors.WithStack(dpp.ErrOutboxMessageNotFound)
+}
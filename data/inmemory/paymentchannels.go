@@ -0,0 +1,28 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PaymentChannel will return the peer channel opened for the payment txid.
+func (s *Store) PaymentChannel(ctx context.Context, txID string) (*dpp.PaymentChannel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c, ok := s.paymentChannels[txID]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrPaymentChannelNotFound)
+	}
+	return &c, nil
+}
+
+// PaymentChannelCreate will store the peer channel against the payment txid.
+func (s *Store) PaymentChannelCreate(ctx context.Context, req dpp.PaymentChannel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paymentChannels[req.TxID] = req
+	return nil
+}
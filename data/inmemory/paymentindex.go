@@ -0,0 +1,87 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PaymentIndex will return the index entry for a txid.
+func (s *Store) PaymentIndex(ctx context.Context, txID string) (*dpp.PaymentIndexEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.paymentIndex[txID]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrPaymentIndexNotFound)
+	}
+	return &e, nil
+}
+
+// PaymentIndexCreate will index the txid and outpoints, failing if any are already indexed.
+func (s *Store) PaymentIndexCreate(ctx context.Context, req dpp.PaymentIndexEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.paymentIndex[req.TxID]; ok {
+		reason := dpp.PaymentConflictTxReused
+		if e.PaymentID == req.PaymentID {
+			reason = dpp.PaymentConflictInProgress
+		}
+		return dpp.PaymentConflictError{
+			Reason:            reason,
+			PaymentID:         req.PaymentID,
+			TxID:              req.TxID,
+			ConflictPaymentID: e.PaymentID,
+			ConflictTxID:      e.TxID,
+		}
+	}
+	for _, o := range req.Outpoints {
+		txID, ok := s.spentOutpoints[o]
+		if !ok {
+			continue
+		}
+		return dpp.PaymentConflictError{
+			Reason:            dpp.PaymentConflictOutpointSpent,
+			PaymentID:         req.PaymentID,
+			TxID:              req.TxID,
+			ConflictPaymentID: s.paymentIndex[txID].PaymentID,
+			ConflictTxID:      txID,
+			Outpoint:          o,
+		}
+	}
+	req.Outpoints = append([]string{}, req.Outpoints...)
+	s.paymentIndex[req.TxID] = req
+	for _, o := range req.Outpoints {
+		s.spentOutpoints[o] = req.TxID
+	}
+	return nil
+}
+
+// PaymentIndexUpdate will store the ACK against an indexed txid.
+func (s *Store) PaymentIndexUpdate(ctx context.Context, txID string, ack dpp.PaymentACK) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.paymentIndex[txID]
+	if !ok {
+		return errors.WithStack(dpp.ErrPaymentIndexNotFound)
+	}
+	e.ACK = &ack
+	s.paymentIndex[txID] = e
+	return nil
+}
+
+// PaymentIndexDelete will remove the txid and its outpoints from the index.
+func (s *Store) PaymentIndexDelete(ctx context.Context, txID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.paymentIndex[txID]
+	if !ok {
+		return nil
+	}
+	for _, o := range e.Outpoints {
+		delete(s.spentOutpoints, o)
+	}
+	delete(s.paymentIndex, txID)
+	return nil
+}
@@ -0,0 +1,70 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PaymentRequest will return a stored PaymentRequest by its paymentID.
+func (s *Store) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pr, ok := s.paymentRequests[args.PaymentID]
+	if !ok {
+		return nil, errors.Errorf("payment request %s not found", args.PaymentID)
+	}
+	return copyPaymentRequest(pr), nil
+}
+
+// PaymentRequestCreate will store a new PaymentRequest, an error is returned if it already exists.
+func (s *Store) PaymentRequestCreate(ctx context.Context, args dpp.PaymentRequestArgs, req dpp.PaymentRequest) (*dpp.PaymentRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.paymentRequests[args.PaymentID]; ok {
+		return nil, errors.Errorf("payment request %s already exists", args.PaymentID)
+	}
+	s.paymentRequests[args.PaymentID] = *copyPaymentRequest(req)
+	return copyPaymentRequest(req), nil
+}
+
+// copyPaymentRequest copies the beneficiary, destinations and modes so callers can modify
+// the returned PaymentRequest without changing the stored one, the FeeRate is shared.
+func copyPaymentRequest(pr dpp.PaymentRequest) *dpp.PaymentRequest {
+	pr.Beneficiary = copyBeneficiary(pr.Payee())
+	pr.MerchantData = nil
+	pr.Destinations.Outputs = append([]dpp.Output(nil), pr.Destinations.Outputs...)
+	if pr.Destinations.Data != nil {
+		data := make([]dpp.DataOutput, len(pr.Destinations.Data))
+		for i, d := range pr.Destinations.Data {
+			d.Pushes = append([]string(nil), d.Pushes...)
+			data[i] = d
+		}
+		pr.Destinations.Data = data
+	}
+	if pr.Modes != nil {
+		modes := make(map[string]json.RawMessage, len(pr.Modes))
+		for k, v := range pr.Modes {
+			modes[k] = append(json.RawMessage(nil), v...)
+		}
+		pr.Modes = modes
+	}
+	return &pr
+}
+
+func copyBeneficiary(b *dpp.Beneficiary) *dpp.Beneficiary {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	if b.ExtendedData != nil {
+		c.ExtendedData = make(map[string]interface{}, len(b.ExtendedData))
+		for k, v := range b.ExtendedData {
+			c.ExtendedData[k] = v
+		}
+	}
+	return &c
+}
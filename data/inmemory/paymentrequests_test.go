@@ -0,0 +1,40 @@
+package inmemory_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+)
+
+func TestStore_PaymentRequest_Copy(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	args := dpp.PaymentRequestArgs{PaymentID: "abc123"}
+	_, err := store.PaymentRequestCreate(ctx, args, dpp.PaymentRequest{
+		Destinations: dpp.PaymentDestinations{
+			Outputs: []dpp.Output{{Amount: 1000}},
+			Data:    []dpp.DataOutput{{Pushes: []string{"0102"}}},
+		},
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123", ExtendedData: map[string]interface{}{"a": "b"}},
+	})
+	is.NoErr(err)
+
+	pr, err := store.PaymentRequest(ctx, args)
+	is.NoErr(err)
+	pr.Beneficiary.AuthTag = "tag"
+	pr.Beneficiary.ExtendedData["a"] = "c"
+	pr.Destinations.Outputs[0].Amount = 1
+	pr.Destinations.Data[0].Pushes[0] = "ff"
+
+	pr, err = store.PaymentRequest(ctx, args)
+	is.NoErr(err)
+	is.Equal(pr.Beneficiary.AuthTag, "")
+	is.Equal(pr.Beneficiary.ExtendedData["a"], "b")
+	is.Equal(pr.Destinations.Outputs[0].Amount, uint64(1000))
+	is.Equal(pr.Destinations.Data[0].Pushes[0], "0102")
+}
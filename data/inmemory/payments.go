@@ -0,0 +1,128 @@
+package inmemory
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// paymentRecord is a stored payment along with its insert sequence which is used as a cursor.
+type paymentRecord struct {
+	seq uint64
+	dpp.PaymentRecord
+}
+
+// PaymentCreate will store the payment against the paymentID, along with its outbox messages, and acknowledge it.
+//
+// Only payments supplying a rawTx are supported. The amount recorded is the sum of outputs paying the
+// destinations of the stored PaymentRequest, or all outputs if there is no PaymentRequest stored.
+func (s *Store) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if req.RawTx == nil {
+		return nil, errors.New("in memory store requires a rawTx")
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid rawTx supplied")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec := dpp.PaymentRecord{
+		PaymentID: args.PaymentID,
+		TxID:      tx.TxID(),
+		Payment:   req,
+		CreatedAt: time.Now().UTC(),
+	}
+	rec.PaymentReference = req.MerchantData.Reference()
+	pr, hasRequest := s.paymentRequests[args.PaymentID]
+	if b := pr.Payee(); hasRequest && b != nil {
+		rec.Merchant = b.Name
+	}
+	for _, o := range tx.Outputs {
+		if !hasRequest {
+			rec.Amount += o.Satoshis
+			continue
+		}
+		for _, d := range pr.Destinations.Outputs {
+			if d.LockingScript != nil && d.LockingScript.Equals(o.LockingScript) {
+				rec.Amount += o.Satoshis
+				break
+			}
+		}
+	}
+	ack := &dpp.PaymentACK{
+		ID:   args.PaymentID,
+		TxID: rec.TxID,
+		Memo: req.Memo,
+	}
+	var outbox []dpp.OutboxMessage
+	if args.Outbox != nil {
+		if outbox, err = args.Outbox(*ack); err != nil {
+			return nil, err
+		}
+	}
+	s.paymentSeq++
+	s.payments = append(s.payments, paymentRecord{seq: s.paymentSeq, PaymentRecord: rec})
+	s.outbox = append(s.outbox, outbox...)
+	return ack, nil
+}
+
+// Payments will return stored payments matching the filters, newest first.
+// The record state is read from the invoice at query time.
+func (s *Store) Payments(ctx context.Context, args dpp.PaymentsArgs) (*dpp.PaymentsPage, error) {
+	before, err := decodeCursor(args.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = dpp.PaymentsDefaultLimit
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	page := &dpp.PaymentsPage{Payments: []dpp.PaymentRecord{}}
+	var lastSeq uint64
+	for i := len(s.payments) - 1; i >= 0; i-- {
+		rec := s.payments[i]
+		if before > 0 && rec.seq >= before {
+			continue
+		}
+		if inv, ok := s.invoices[rec.PaymentID]; ok {
+			rec.State = inv.State
+		}
+		if !args.Matches(rec.PaymentRecord) {
+			continue
+		}
+		if len(page.Payments) == limit {
+			page.NextCursor = encodeCursor(lastSeq)
+			break
+		}
+		page.Payments = append(page.Payments, rec.PaymentRecord)
+		lastSeq = rec.seq
+	}
+	return page, nil
+}
+
+func encodeCursor(seq uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(seq, 10)))
+}
+
+func decodeCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid cursor")
+	}
+	seq, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid cursor")
+	}
+	return seq, nil
+}
@@ -0,0 +1,73 @@
+package inmemory_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+)
+
+func TestStore_Payments(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	for i := 0; i < 5; i++ {
+		paymentID := fmt.Sprintf("payment%d", i)
+		_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: paymentID}, dpp.PaymentRequest{
+			Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: uint64(1000 * (i + 1)), LockingScript: script}}},
+			Beneficiary:  &dpp.Beneficiary{Name: "merchant"},
+		})
+		is.NoErr(err)
+		tx := bt.NewTx()
+		is.NoErr(tx.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", uint32(i), script.String(), 10000))
+		is.NoErr(tx.PayTo(script, uint64(1000*(i+1))))
+		rawTx := tx.String()
+		ack, err := store.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: paymentID}, dpp.Payment{
+			RawTx: &rawTx,
+			MerchantData: dpp.Merchant{ExtendedData: map[string]interface{}{
+				"paymentReference": paymentID,
+			}},
+		})
+		is.NoErr(err)
+		is.Equal(ack.TxID, tx.TxID())
+	}
+
+	page, err := store.Payments(ctx, dpp.PaymentsArgs{Limit: 2})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 2)
+	is.Equal(page.Payments[0].PaymentID, "payment4")
+	is.Equal(page.Payments[1].PaymentID, "payment3")
+	is.True(page.NextCursor != "")
+
+	page, err = store.Payments(ctx, dpp.PaymentsArgs{Limit: 2, Cursor: page.NextCursor})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 2)
+	is.Equal(page.Payments[0].PaymentID, "payment2")
+	is.Equal(page.Payments[1].PaymentID, "payment1")
+
+	page, err = store.Payments(ctx, dpp.PaymentsArgs{Limit: 2, Cursor: page.NextCursor})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 1)
+	is.Equal(page.NextCursor, "")
+
+	page, err = store.Payments(ctx, dpp.PaymentsArgs{MinAmount: 2000, MaxAmount: 3000, Merchant: "merchant"})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 2)
+	is.Equal(page.Payments[0].Amount, uint64(3000))
+	is.Equal(page.Payments[1].Amount, uint64(2000))
+
+	page, err = store.Payments(ctx, dpp.PaymentsArgs{PaymentReference: "payment0"})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 1)
+
+	_, err = store.Payments(ctx, dpp.PaymentsArgs{Cursor: "!!"})
+	is.True(err != nil)
+}
@@ -0,0 +1,41 @@
+package inmemory
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+
+	"github.com/libsv/go-dpp"
+)
+
+// ProofCreate will store the proof envelope and queue its outbox messages.
+func (s *Store) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proofs = append(s.proofs, dpp.ProofRecord{
+		TxID:             args.TxID,
+		PaymentReference: args.PaymentReference,
+		Envelope:         req,
+		CreatedAt:        time.Now().UTC(),
+	})
+	s.outbox = append(s.outbox, args.Outbox...)
+	return nil
+}
+
+// Proofs will return stored proofs matching the args, oldest first.
+func (s *Store) Proofs(ctx context.Context, args dpp.ProofsArgs) ([]dpp.ProofRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	pp := make([]dpp.ProofRecord, 0)
+	for _, p := range s.proofs {
+		switch {
+		case args.TxID != "" && p.TxID != args.TxID,
+			!args.From.IsZero() && p.CreatedAt.Before(args.From),
+			!args.To.IsZero() && !p.CreatedAt.Before(args.To):
+			continue
+		}
+		pp = append(pp, p)
+	}
+	return pp, nil
+}
@@ -0,0 +1,28 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// ProofToken will return the token issued for the txid.
+func (s *Store) ProofToken(ctx context.Context, txID string) (*dpp.ProofToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	t, ok := s.proofTokens[txID]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrProofTokenNotFound)
+	}
+	return &t, nil
+}
+
+// ProofTokenCreate will store the token against its txid.
+func (s *Store) ProofTokenCreate(ctx context.Context, req dpp.ProofToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.proofTokens[req.TxID] = req
+	return nil
+}
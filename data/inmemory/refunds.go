@@ -0,0 +1,39 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Refunds will return the refunds stored for the payment, oldest first.
+func (s *Store) Refunds(ctx context.Context, args dpp.RefundArgs) ([]dpp.Refund, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]dpp.Refund{}, s.refunds[args.PaymentID]...), nil
+}
+
+// RefundCreate will store the refund against its payment.
+func (s *Store) RefundCreate(ctx context.Context, req dpp.Refund) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refunds[req.PaymentID] = append(s.refunds[req.PaymentID], req)
+	return nil
+}
+
+// RefundUpdate will replace the most recent stored refund with the same PaymentID and TxID,
+// a tx that failed to broadcast can be signed again with the same id.
+func (s *Store) RefundUpdate(ctx context.Context, req dpp.Refund) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	refunds := s.refunds[req.PaymentID]
+	for i := len(refunds) - 1; i >= 0; i-- {
+		if refunds[i].TxID == req.TxID {
+			refunds[i] = req
+			return nil
+		}
+	}
+	return errors.Wrapf(dpp.ErrRefundNotFound, "refund tx %s", req.TxID)
+}
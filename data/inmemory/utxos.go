@@ -0,0 +1,32 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// UTXO will return a stored output.
+func (s *Store) UTXO(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.utxos[fmt.Sprintf("%s:%d", txID, vout)]
+	if !ok {
+		return nil, errors.WithStack(dpp.ErrUTXONotFound)
+	}
+	return &u, nil
+}
+
+// UTXOCreate will store the outputs, replacing any already stored.
+func (s *Store) UTXOCreate(ctx context.Context, utxos ...*bt.UTXO) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range utxos {
+		s.utxos[fmt.Sprintf("%s:%d", u.TxIDStr(), u.Vout)] = *u
+	}
+	return nil
+}
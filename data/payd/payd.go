@@ -0,0 +1,195 @@
+// Package payd implements the dpp data interfaces by calling a PayD wallet over HTTP.
+//
+// DPP acts as a proxy in front of the wallet, reading payment destinations and merchant
+// details from it to build PaymentRequests and passing received Payments to it.
+package payd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PayD wallet endpoints.
+const (
+	pathDestinations = "%s/api/v1/destinations/%s"
+	pathPayments     = "%s/api/v1/payments/%s"
+	pathOwner        = "%s/api/v1/owner"
+)
+
+// Config contains the settings used to connect to a PayD wallet.
+type Config struct {
+	// Host is the host name of the wallet, ie payd.
+	Host string
+	// Port is the port the wallet listens on including the colon, ie :8443.
+	Port string
+	// Secure if true will validate the wallet TLS certificates.
+	Secure bool
+	// PaymentURL is the base url of this DPP server that payments are sent to,
+	// the paymentID is appended to it.
+	PaymentURL string
+	// Timeout is the maximum time to wait for the wallet to respond, defaults to 30 seconds.
+	Timeout time.Duration
+}
+
+// PayD implements dpp.PaymentWriter and dpp.PaymentRequestReader by calling a PayD wallet.
+type PayD struct {
+	cfg    *Config
+	client *http.Client
+}
+
+// NewPayD will setup and return a new PayD data store.
+func NewPayD(cfg *Config) *PayD {
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	return &PayD{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: timeout,
+			Transport: &http.Transport{
+				// nolint:gosec // verification is disabled deliberately when not running securely.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: !cfg.Secure},
+			},
+		},
+	}
+}
+
+// PaymentRequest will read the destinations for a payment and the merchant details from
+// the wallet and combine them into a PaymentRequest.
+func (p *PayD) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	var dests dpp.Destinations
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf(pathDestinations, p.baseURL(), args.PaymentID), nil, &dests); err != nil {
+		return nil, errors.Wrapf(err, "failed to read destinations for payment %s", args.PaymentID)
+	}
+	var owner dpp.Beneficiary
+	if err := p.do(ctx, http.MethodGet, fmt.Sprintf(pathOwner, p.baseURL()), nil, &owner); err != nil {
+		return nil, errors.Wrap(err, "failed to read wallet owner")
+	}
+	owner.PaymentReference = args.PaymentID
+	return &dpp.PaymentRequest{
+		Network:             dests.Network,
+		AncestryRequired:    dests.AncestryRequired,
+		Destinations:        dpp.PaymentDestinations{Outputs: dests.Outputs},
+		CreationTimestamp:   dests.CreatedAt,
+		ExpirationTimestamp: dests.ExpiresAt,
+		PaymentURL:          fmt.Sprintf("%s/%s", strings.TrimSuffix(p.cfg.PaymentURL, "/"), args.PaymentID),
+		Beneficiary:         &owner,
+		FeeRate:             dests.Fees,
+	}, nil
+}
+
+// PaymentCreate will send the payment to the wallet to be validated and broadcast.
+//
+// If the wallet rejects the payment, with a 400, 409 or 422, a PaymentACK with Error set
+// is returned containing the reason in the Memo. Any other error status, such as a 401
+// or 404 caused by misconfiguration, is returned as an error.
+//
+// The ProofToken, if issued, is sent as the callbackToken the wallet uses when broadcasting
+// to mAPI, mAPI sends it as a bearer token with each proof callback.
+//
+// PayD cannot store webhook outbox messages with the payment so an error is returned if
+// args.Outbox is set, ie when service.WithWebhooks is used with this writer.
+func (p *PayD) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if args.Outbox != nil {
+		return nil, errors.New("payd does not support webhook outbox messages")
+	}
+	var ack dpp.PaymentACK
+	err := p.do(ctx, http.MethodPost, fmt.Sprintf(pathPayments, p.baseURL(), args.PaymentID), PaymentCreateRequest{
+		Payment:       req,
+		CallbackToken: args.ProofToken,
+	}, &ack)
+	if err == nil {
+		return &ack, nil
+	}
+	var errResp ErrResponse
+	if errors.As(err, &errResp) && rejected(errResp.StatusCode) {
+		return &dpp.PaymentACK{
+			ID:    args.PaymentID,
+			Memo:  errResp.Message,
+			Error: 1,
+		}, nil
+	}
+	return nil, errors.Wrapf(err, "failed to send payment %s to wallet", args.PaymentID)
+}
+
+// PaymentCreateRequest is the body sent to the wallet payments endpoint.
+type PaymentCreateRequest struct {
+	dpp.Payment
+	// CallbackToken is the token mAPI must send with proof callbacks for the transaction.
+	CallbackToken string `json:"callbackToken,omitempty"`
+}
+
+// rejected returns true if the status code means the wallet rejected the payment.
+func rejected(status int) bool {
+	switch status {
+	case http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+func (p *PayD) baseURL() string {
+	return fmt.Sprintf("https://%s%s", p.cfg.Host, p.cfg.Port)
+}
+
+// do will send the request, marshalling req to JSON if supplied, and unmarshal
+// a successful response into out.
+func (p *PayD) do(ctx context.Context, method, url string, req, out interface{}) error {
+	var body io.Reader
+	if req != nil {
+		bb, err := json.Marshal(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request")
+		}
+		body = bytes.NewReader(bb)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		errResp := ErrResponse{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(bb, &errResp); err != nil || errResp.Message == "" {
+			errResp.Message = strings.TrimSpace(string(bb))
+		}
+		return errResp
+	}
+	if out == nil || len(bb) == 0 {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(bb, out), "failed to decode response")
+}
+
+// ErrResponse is returned when the wallet responds with an error status code.
+type ErrResponse struct {
+	StatusCode int    `json:"-"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+}
+
+// Error satisfies the error interface.
+func (e ErrResponse) Error() string {
+	return fmt.Sprintf("payd responded with status %d: %s", e.StatusCode, e.Message)
+}
@@ -0,0 +1,104 @@
+package payd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/payd"
+	"github.com/libsv/go-dpp/data/payd/paydtest"
+)
+
+func TestPayD_PaymentRequest(t *testing.T) {
+	is := is.New(t)
+	srv := paydtest.NewServer(dpp.Beneficiary{Name: "merchant 1", Email: "merchant@m.com"})
+	defer srv.Close()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	created := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	srv.AddDestinations("abc123", dpp.Destinations{
+		Network:   "testnet",
+		Outputs:   []dpp.Output{{Amount: 1000, LockingScript: script}},
+		Fees:      bt.NewFeeQuote(),
+		CreatedAt: created,
+		ExpiresAt: created.Add(time.Hour),
+	})
+	p := payd.NewPayD(srv.Config("https://dpp/api/v1/payment"))
+
+	pr, err := p.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	is.Equal(pr.Network, "testnet")
+	is.Equal(pr.PaymentURL, "https://dpp/api/v1/payment/abc123")
+	is.Equal(pr.Beneficiary.Name, "merchant 1")
+	is.Equal(pr.Beneficiary.PaymentReference, "abc123")
+	is.Equal(len(pr.Destinations.Outputs), 1)
+	is.True(pr.Destinations.Outputs[0].LockingScript.Equals(script))
+	is.True(pr.CreationTimestamp.Equal(created))
+
+	_, err = p.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "missing"})
+	is.True(err != nil)
+}
+
+func TestPayD_PaymentCreate(t *testing.T) {
+	is := is.New(t)
+	srv := paydtest.NewServer(dpp.Beneficiary{Name: "merchant 1"})
+	defer srv.Close()
+	srv.AddDestinations("abc123", dpp.Destinations{Network: "testnet"})
+	p := payd.NewPayD(srv.Config("https://dpp/api/v1/payment"))
+
+	tx := bt.NewTx()
+	is.NoErr(tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1000))
+	rawTx := tx.String()
+	ack, err := p.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123", ProofToken: "secret"}, dpp.Payment{
+		RawTx: &rawTx,
+		Memo:  "thanks",
+	})
+	is.NoErr(err)
+	is.Equal(ack, &dpp.PaymentACK{ID: "abc123", TxID: tx.TxID(), Memo: "thanks"})
+	is.Equal(len(srv.Payments("abc123")), 1)
+	// the proof token is sent as the mAPI callbackToken.
+	is.Equal(srv.CallbackTokens("abc123"), []string{"secret"})
+
+	ack, err = p.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{})
+	is.NoErr(err)
+	is.Equal(ack, &dpp.PaymentACK{ID: "abc123", Memo: "rawTx is required", Error: 1})
+
+	// a missing payment is a misconfiguration, not a rejection.
+	ack, err = p.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "missing"}, dpp.Payment{RawTx: &rawTx})
+	is.True(err != nil)
+	is.True(ack == nil)
+	var errResp payd.ErrResponse
+	is.True(errors.As(err, &errResp))
+	is.Equal(errResp.StatusCode, http.StatusNotFound)
+
+	// webhooks cannot be stored with the payment so are refused rather than lost.
+	_, err = p.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{
+		PaymentID: "abc123",
+		Outbox: func(ack dpp.PaymentACK) ([]dpp.OutboxMessage, error) {
+			return nil, nil
+		},
+	}, dpp.Payment{RawTx: &rawTx})
+	is.True(err != nil)
+	is.Equal(len(srv.Payments("abc123")), 1)
+}
+
+func TestPayD_Secure(t *testing.T) {
+	is := is.New(t)
+	srv := paydtest.NewServer(dpp.Beneficiary{})
+	defer srv.Close()
+	srv.AddDestinations("abc123", dpp.Destinations{})
+	cfg := srv.Config("https://dpp/api/v1/payment")
+	cfg.Secure = true
+	p := payd.NewPayD(cfg)
+
+	// the fake server uses a self signed certificate so verification fails.
+	_, err := p.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.True(err != nil)
+}
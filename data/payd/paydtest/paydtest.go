@@ -0,0 +1,150 @@
+// Package paydtest provides an in-process fake PayD wallet for use in tests.
+package paydtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/libsv/go-bt/v2"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/payd"
+)
+
+// Server is a fake PayD wallet serving the destinations, owner and payments endpoints over TLS.
+//
+// Destinations must be added before they are requested, payments are accepted if they
+// contain a valid rawTx and their destinations exist, otherwise they are rejected.
+type Server struct {
+	mu           sync.RWMutex
+	srv          *httptest.Server
+	owner        dpp.Beneficiary
+	destinations map[string]dpp.Destinations
+	payments     map[string][]dpp.Payment
+	tokens       map[string][]string
+}
+
+// NewServer will start and return a new fake PayD wallet, Close should be called when finished.
+func NewServer(owner dpp.Beneficiary) *Server {
+	s := &Server{
+		owner:        owner,
+		destinations: map[string]dpp.Destinations{},
+		payments:     map[string][]dpp.Payment{},
+		tokens:       map[string][]string{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/owner", s.handleOwner)
+	mux.HandleFunc("/api/v1/destinations/", s.handleDestinations)
+	mux.HandleFunc("/api/v1/payments/", s.handlePayments)
+	s.srv = httptest.NewTLSServer(mux)
+	return s
+}
+
+// Config returns a payd.Config that connects to this server.
+// TLS verification is disabled as the server uses a self signed certificate.
+func (s *Server) Config(paymentURL string) *payd.Config {
+	u, _ := url.Parse(s.srv.URL)
+	return &payd.Config{
+		Host:       u.Hostname(),
+		Port:       ":" + u.Port(),
+		PaymentURL: paymentURL,
+	}
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// AddDestinations will store destinations to be returned for a paymentID.
+func (s *Server) AddDestinations(paymentID string, d dpp.Destinations) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destinations[paymentID] = d
+}
+
+// Payments returns the payments received for a paymentID.
+func (s *Server) Payments(paymentID string) []dpp.Payment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]dpp.Payment{}, s.payments[paymentID]...)
+}
+
+// CallbackTokens returns the callbackToken sent with each payment received for a paymentID.
+func (s *Server) CallbackTokens(paymentID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.tokens[paymentID]...)
+}
+
+func (s *Server) handleOwner(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.owner)
+}
+
+func (s *Server) handleDestinations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.mu.RLock()
+	d, ok := s.destinations[strings.TrimPrefix(r.URL.Path, "/api/v1/destinations/")]
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "destinations not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (s *Server) handlePayments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	paymentID := strings.TrimPrefix(r.URL.Path, "/api/v1/payments/")
+	var req payd.PaymentCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid payment body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.destinations[paymentID]; !ok {
+		writeError(w, http.StatusNotFound, "payment not found")
+		return
+	}
+	if req.RawTx == nil {
+		writeError(w, http.StatusUnprocessableEntity, "rawTx is required")
+		return
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "invalid rawTx")
+		return
+	}
+	s.payments[paymentID] = append(s.payments[paymentID], req.Payment)
+	s.tokens[paymentID] = append(s.tokens[paymentID], req.CallbackToken)
+	writeJSON(w, http.StatusCreated, dpp.PaymentACK{
+		ID:   paymentID,
+		TxID: tx.TxID(),
+		Memo: req.Memo,
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, payd.ErrResponse{Title: http.StatusText(status), Message: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
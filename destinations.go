@@ -1,10 +1,12 @@
 package dpp
 
 import (
+	"context"
 	"time"
 
 	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
 )
 
 // Output message used in BIP270.
@@ -14,14 +16,46 @@ type Output struct {
 	Amount uint64 `json:"amount" example:"100000"`
 	// Script is a locking script where payment should be sent, formatted as a hexadecimal string.
 	LockingScript *bscript.Script `json:"script" swaggertype:"primitive,string" example:"76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"`
+	// BIP276 is an optional BIP276 encoded script, ie bitcoin-script:0101<script><checksum>.
+	// When supplied its network must match the request network and the LockingScript is decoded from it.
+	BIP276 string `json:"bip276,omitempty" example:"bitcoin-script:010176a91455b61be43392125d127f1780fb038437cd67ef9c88acd98517f2"`
 	// Description, an optional description such as "tip" or "sales tax". Maximum length is 100 chars.
 	Description string `json:"description" example:"paymentReference 123456"`
 }
 
+// DataOutput describes a zero value OP_FALSE OP_RETURN output that a payment must
+// contain, such as an order hash being anchored on chain.
+type DataOutput struct {
+	// Pushes are the hex encoded data pushes following OP_FALSE OP_RETURN.
+	Pushes []string `json:"pushes" example:"6f726465723a3132333435,9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	// Description, an optional description such as "order hash". Maximum length is 100 chars.
+	Description string `json:"description" example:"order hash"`
+}
+
+// LockingScript returns the OP_FALSE OP_RETURN script containing the pushes.
+func (d DataOutput) LockingScript() (*bscript.Script, error) {
+	if len(d.Pushes) == 0 {
+		return nil, errors.New("at least one push is required")
+	}
+	s := &bscript.Script{}
+	if err := s.AppendOpcodes(bscript.OpFALSE, bscript.OpRETURN); err != nil {
+		return nil, err
+	}
+	for i, p := range d.Pushes {
+		if err := s.AppendPushDataHexString(p); err != nil {
+			return nil, errors.Wrapf(err, "invalid push %d", i)
+		}
+	}
+	return s, nil
+}
+
 // PaymentDestinations contains the supported destinations
 // by this DPP server.
 type PaymentDestinations struct {
 	Outputs []Output `json:"outputs"`
+	// Data are OP_RETURN outputs the payment transaction must contain exactly, they
+	// are charged at the data fee rate.
+	Data []DataOutput `json:"data,omitempty"`
 }
 
 // Destinations message containing outputs and their fees.
@@ -33,3 +67,53 @@ type Destinations struct {
 	CreatedAt        time.Time    `json:"createdAt"`
 	ExpiresAt        time.Time    `json:"expiresAt"`
 }
+
+// DestinationsArgs identifies the invoice that destinations are being created for.
+type DestinationsArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// DestinationsCreate contains the amounts that destinations are required for.
+type DestinationsCreate struct {
+	// Amounts are the satoshi values to create outputs for.
+	Amounts []uint64
+}
+
+// DestinationsCreator will generate locking scripts to receive payment
+// for an invoice, the implementation decides how scripts are derived.
+type DestinationsCreator interface {
+	DestinationsCreate(ctx context.Context, args DestinationsArgs, req DestinationsCreate) (*PaymentDestinations, error)
+}
+
+// DerivationIndexArgs identifies the key a derivation index is being reserved for.
+type DerivationIndexArgs struct {
+	// KeyID identifies the extended key and path template indexes are used with.
+	KeyID string
+	// Count is the number of consecutive indexes to reserve.
+	Count uint32
+}
+
+// DerivationIndexLimit is the first hardened child index, reserved indexes must stay
+// below it as hardened children cannot be derived from a public key.
+const DerivationIndexLimit uint32 = 1 << 31
+
+// ErrDerivationIndexExhausted is returned when reserving indexes would reach DerivationIndexLimit.
+var ErrDerivationIndexExhausted = errors.New("derivation indexes exhausted")
+
+// DerivationIndexWriter reserves indexes used to derive child keys. Once an index
+// is reserved it must never be returned again, even after a restart, and indexes
+// must not reach DerivationIndexLimit.
+type DerivationIndexWriter interface {
+	// DerivationIndexReserve reserves Count indexes and returns the first of them.
+	DerivationIndexReserve(ctx context.Context, args DerivationIndexArgs) (uint32, error)
+}
+
+// LockingScriptFunc returns a new locking script to receive an output to.
+type LockingScriptFunc func(ctx context.Context) (*bscript.Script, error)
+
+// DestinationStrategy decides how an amount is split across outputs.
+type DestinationStrategy interface {
+	// Outputs splits total into outputs whose amounts sum to total, calling next to get
+	// the locking script for each. Output descriptions are label followed by the part number.
+	Outputs(ctx context.Context, total uint64, label string, next LockingScriptFunc) ([]Output, error)
+}
@@ -0,0 +1,130 @@
+package destinations
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// MaxOutputs is the maximum number of outputs a strategy will split an amount into.
+const MaxOutputs = 100
+
+type single struct{}
+
+// NewSingle returns a DestinationStrategy that pays the total to a single output.
+func NewSingle() dpp.DestinationStrategy {
+	return single{}
+}
+
+// Outputs returns one output for the total.
+func (s single) Outputs(ctx context.Context, total uint64, label string, next dpp.LockingScriptFunc) ([]dpp.Output, error) {
+	return outputs(ctx, []uint64{total}, label, next)
+}
+
+type denominations struct {
+	denoms []uint64
+}
+
+// NewDenominations returns a DestinationStrategy that splits the total into outputs of the
+// fixed denominations supplied, largest first. Any remainder smaller than the smallest
+// denomination is paid to a final output.
+func NewDenominations(denoms ...uint64) (dpp.DestinationStrategy, error) {
+	if len(denoms) == 0 {
+		return nil, errors.New("at least one denomination is required")
+	}
+	dd := append([]uint64{}, denoms...)
+	sort.Slice(dd, func(i, j int) bool { return dd[i] > dd[j] })
+	if dd[len(dd)-1] == 0 {
+		return nil, errors.New("denominations must be greater than 0")
+	}
+	return denominations{denoms: dd}, nil
+}
+
+// Outputs splits the total into the fixed denominations.
+func (d denominations) Outputs(ctx context.Context, total uint64, label string, next dpp.LockingScriptFunc) ([]dpp.Output, error) {
+	amounts := make([]uint64, 0)
+	remaining := total
+	for _, denom := range d.denoms {
+		n := remaining / denom
+		if uint64(len(amounts))+n > MaxOutputs {
+			return nil, errors.Errorf("splitting %d into denominations requires more than %d outputs", total, MaxOutputs)
+		}
+		for i := uint64(0); i < n; i++ {
+			amounts = append(amounts, denom)
+		}
+		remaining -= n * denom
+	}
+	if remaining > 0 {
+		amounts = append(amounts, remaining)
+	}
+	return outputs(ctx, amounts, label, next)
+}
+
+type randomSplit struct {
+	min uint64
+	max uint64
+}
+
+// NewRandomSplit returns a DestinationStrategy that splits the total into outputs of random
+// amounts between min and max inclusive. max must be at least twice min so any total can be
+// split, a total smaller than min is paid to a single output.
+func NewRandomSplit(min, max uint64) (dpp.DestinationStrategy, error) {
+	if min == 0 {
+		return nil, errors.New("min must be greater than 0")
+	}
+	if max < min*2 {
+		return nil, errors.Errorf("max %d must be at least twice min %d", max, min)
+	}
+	return randomSplit{min: min, max: max}, nil
+}
+
+// Outputs splits the total into random amounts.
+func (r randomSplit) Outputs(ctx context.Context, total uint64, label string, next dpp.LockingScriptFunc) ([]dpp.Output, error) {
+	amounts := make([]uint64, 0)
+	remaining := total
+	for remaining > r.max {
+		if len(amounts) == MaxOutputs {
+			return nil, errors.Errorf("splitting %d between %d and %d requires more than %d outputs", total, r.min, r.max, MaxOutputs)
+		}
+		n, err := rand.Int(rand.Reader, new(big.Int).SetUint64(r.max-r.min+1))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate random amount")
+		}
+		amount := r.min + n.Uint64()
+		// never leave a remainder smaller than min.
+		if remaining-amount < r.min {
+			amount = remaining - r.min
+		}
+		amounts = append(amounts, amount)
+		remaining -= amount
+	}
+	amounts = append(amounts, remaining)
+	return outputs(ctx, amounts, label, next)
+}
+
+// outputs creates an output for each amount, labelling each with its part number.
+func outputs(ctx context.Context, amounts []uint64, label string, next dpp.LockingScriptFunc) ([]dpp.Output, error) {
+	oo := make([]dpp.Output, 0, len(amounts))
+	for i, amount := range amounts {
+		script, err := next(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get locking script")
+		}
+		desc := label
+		if len(amounts) > 1 {
+			desc = fmt.Sprintf("%s (part %d of %d)", label, i+1, len(amounts))
+		}
+		oo = append(oo, dpp.Output{
+			Amount:        amount,
+			LockingScript: script,
+			Description:   desc,
+		})
+	}
+	return oo, nil
+}
@@ -0,0 +1,128 @@
+package destinations_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/destinations"
+)
+
+func nextScript(ctx context.Context) (*bscript.Script, error) {
+	return bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+}
+
+func TestDenominations_Outputs(t *testing.T) {
+	tests := map[string]struct {
+		denoms  []uint64
+		total   uint64
+		amounts []uint64
+		err     bool
+	}{
+		"exact denominations": {
+			denoms:  []uint64{100, 1000},
+			total:   2200,
+			amounts: []uint64{1000, 1000, 100, 100},
+		},
+		"remainder paid to final output": {
+			denoms:  []uint64{1000},
+			total:   2500,
+			amounts: []uint64{1000, 1000, 500},
+		},
+		"total smaller than denominations": {
+			denoms:  []uint64{1000},
+			total:   10,
+			amounts: []uint64{10},
+		},
+		"too many outputs": {
+			denoms: []uint64{1},
+			total:  destinations.MaxOutputs + 1,
+			err:    true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			s, err := destinations.NewDenominations(test.denoms...)
+			is.NoErr(err)
+			oo, err := s.Outputs(context.Background(), test.total, "ref", nextScript)
+			if test.err {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(len(oo), len(test.amounts))
+			for i, o := range oo {
+				is.Equal(o.Amount, test.amounts[i])
+				if len(oo) > 1 {
+					is.Equal(o.Description, fmt.Sprintf("ref (part %d of %d)", i+1, len(oo)))
+				}
+			}
+		})
+	}
+}
+
+func TestNewDenominations(t *testing.T) {
+	is := is.New(t)
+	_, err := destinations.NewDenominations()
+	is.True(err != nil)
+	_, err = destinations.NewDenominations(1000, 0)
+	is.True(err != nil)
+}
+
+func TestRandomSplit_Outputs(t *testing.T) {
+	is := is.New(t)
+	s, err := destinations.NewRandomSplit(100, 300)
+	is.NoErr(err)
+	for _, total := range []uint64{50, 100, 300, 301, 5000, 9999} {
+		oo, err := s.Outputs(context.Background(), total, "ref", nextScript)
+		is.NoErr(err)
+		var sum uint64
+		for _, o := range oo {
+			if total >= 100 {
+				is.True(o.Amount >= 100)
+			}
+			is.True(o.Amount <= 300)
+			sum += o.Amount
+		}
+		is.Equal(sum, total)
+	}
+	_, err = s.Outputs(context.Background(), 300*destinations.MaxOutputs+1, "ref", nextScript)
+	is.True(err != nil)
+
+	_, err = destinations.NewRandomSplit(0, 100)
+	is.True(err != nil)
+	_, err = destinations.NewRandomSplit(100, 199)
+	is.True(err != nil)
+}
+
+func TestXpub_WithStrategy(t *testing.T) {
+	is := is.New(t)
+	master, err := bip32.NewMaster([]byte("000102030405060708090a0b0c0d0e0f"), &chaincfg.TestNet)
+	is.NoErr(err)
+	xpub, err := master.Neuter()
+	is.NoErr(err)
+	s, err := destinations.NewDenominations(1000)
+	is.NoErr(err)
+
+	dc, err := destinations.NewXpub(xpub, "0/%d", inmemory.NewStore(), destinations.WithStrategy(s))
+	is.NoErr(err)
+	dests, err := dc.DestinationsCreate(context.Background(), dpp.DestinationsArgs{PaymentID: "abc123"}, dpp.DestinationsCreate{
+		Amounts: []uint64{2500},
+	})
+	is.NoErr(err)
+	is.Equal(len(dests.Outputs), 3)
+	seen := map[string]bool{}
+	for i, o := range dests.Outputs {
+		is.Equal(o.Description, fmt.Sprintf("paymentReference abc123 (part %d of 3)", i+1))
+		is.True(!seen[o.LockingScript.String()])
+		seen[o.LockingScript.String()] = true
+	}
+}
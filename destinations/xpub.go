@@ -0,0 +1,104 @@
+// Package destinations contains DestinationsCreator implementations used to
+// generate the outputs a merchant wants to be paid to.
+package destinations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type xpub struct {
+	key      *bip32.ExtendedKey
+	template string
+	keyID    string
+	diw      dpp.DerivationIndexWriter
+	strategy dpp.DestinationStrategy
+}
+
+// XpubOpt can be supplied to NewXpub to change its behaviour.
+type XpubOpt func(x *xpub)
+
+// WithStrategy sets the strategy used to split each amount into outputs,
+// by default each amount is paid to a single output.
+func WithStrategy(s dpp.DestinationStrategy) XpubOpt {
+	return func(x *xpub) {
+		x.strategy = s
+	}
+}
+
+// NewXpub will setup and return a DestinationsCreator that derives a fresh P2PKH
+// locking script for each requested amount from an extended public key.
+//
+// The template is a derivation path relative to the key containing a single %d which is
+// replaced by the next unused index, ie "0/%d". Only non-hardened paths can be derived
+// from a public key. Private keys are rejected so they never need to be held by the server.
+func NewXpub(key *bip32.ExtendedKey, template string, diw dpp.DerivationIndexWriter, opts ...XpubOpt) (dpp.DestinationsCreator, error) {
+	if key.IsPrivate() {
+		return nil, errors.New("an extended public key is required, private key supplied")
+	}
+	if strings.Count(template, "%d") != 1 {
+		return nil, errors.Errorf("derivation path template %q must contain a single %%d", template)
+	}
+	if strings.Contains(template, "'") {
+		return nil, errors.Errorf("derivation path template %q cannot contain hardened children", template)
+	}
+	if _, err := key.DeriveChildFromPath(fmt.Sprintf(template, 0)); err != nil {
+		return nil, errors.Wrapf(err, "invalid derivation path template %q", template)
+	}
+	x := &xpub{
+		key:      key,
+		template: template,
+		keyID:    key.String() + "/" + template,
+		diw:      diw,
+		strategy: NewSingle(),
+	}
+	for _, opt := range opts {
+		opt(x)
+	}
+	return x, nil
+}
+
+// DestinationsCreate will split each amount using the strategy and derive a P2PKH
+// locking script for every output from the next unused index.
+func (x *xpub) DestinationsCreate(ctx context.Context, args dpp.DestinationsArgs, req dpp.DestinationsCreate) (*dpp.PaymentDestinations, error) {
+	if len(req.Amounts) == 0 {
+		return nil, errors.New("at least one amount is required")
+	}
+	outputs := make([]dpp.Output, 0, len(req.Amounts))
+	for _, amount := range req.Amounts {
+		oo, err := x.strategy.Outputs(ctx, amount, fmt.Sprintf("paymentReference %s", args.PaymentID), x.lockingScript)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create outputs for payment %s", args.PaymentID)
+		}
+		outputs = append(outputs, oo...)
+	}
+	return &dpp.PaymentDestinations{Outputs: outputs}, nil
+}
+
+// lockingScript reserves the next index and returns a P2PKH locking script derived from it.
+func (x *xpub) lockingScript(ctx context.Context) (*bscript.Script, error) {
+	idx, err := x.diw.DerivationIndexReserve(ctx, dpp.DerivationIndexArgs{
+		KeyID: x.keyID,
+		Count: 1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reserve derivation index")
+	}
+	path := fmt.Sprintf(x.template, idx)
+	pubKey, err := x.key.DerivePublicKeyFromPath(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to derive key for path %s", path)
+	}
+	script, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create locking script for path %s", path)
+	}
+	return script, nil
+}
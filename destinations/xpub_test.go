@@ -0,0 +1,95 @@
+package destinations_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/file"
+	"github.com/libsv/go-dpp/destinations"
+)
+
+func TestXpub_DestinationsCreate(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	master, err := bip32.NewMaster([]byte("000102030405060708090a0b0c0d0e0f"), &chaincfg.TestNet)
+	is.NoErr(err)
+	xpub, err := master.Neuter()
+	is.NoErr(err)
+	indexPath := filepath.Join(t.TempDir(), "indexes.json")
+
+	dc, err := destinations.NewXpub(xpub, "0/%d", file.NewDerivationIndex(indexPath))
+	is.NoErr(err)
+	dests, err := dc.DestinationsCreate(ctx, dpp.DestinationsArgs{PaymentID: "abc123"}, dpp.DestinationsCreate{
+		Amounts: []uint64{1000, 2000},
+	})
+	is.NoErr(err)
+	is.Equal(len(dests.Outputs), 2)
+
+	// a new creator using the same index file simulates a restart.
+	dc, err = destinations.NewXpub(xpub, "0/%d", file.NewDerivationIndex(indexPath))
+	is.NoErr(err)
+	more, err := dc.DestinationsCreate(ctx, dpp.DestinationsArgs{PaymentID: "def456"}, dpp.DestinationsCreate{
+		Amounts: []uint64{3000},
+	})
+	is.NoErr(err)
+	outputs := append(dests.Outputs, more.Outputs...)
+
+	for i, o := range outputs {
+		pub, err := master.DerivePublicKeyFromPath(fmt.Sprintf("0/%d", i))
+		is.NoErr(err)
+		exp, err := bscript.NewP2PKHFromPubKeyBytes(pub)
+		is.NoErr(err)
+		is.True(exp.Equals(o.LockingScript))
+		is.True(o.LockingScript.IsP2PKH())
+	}
+	is.Equal(outputs[2].Amount, uint64(3000))
+	is.Equal(outputs[2].Description, "paymentReference def456")
+}
+
+func TestNewXpub(t *testing.T) {
+	master, err := bip32.NewMaster([]byte("000102030405060708090a0b0c0d0e0f"), &chaincfg.TestNet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xpub, err := master.Neuter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		key      *bip32.ExtendedKey
+		template string
+		expErr   bool
+	}{
+		"public key with template should succeed": {
+			key:      xpub,
+			template: "1/%d",
+		}, "private key should error": {
+			key:      master,
+			template: "0/%d",
+			expErr:   true,
+		}, "template without index should error": {
+			key:      xpub,
+			template: "0/1",
+			expErr:   true,
+		}, "hardened template should error": {
+			key:      xpub,
+			template: "0'/%d",
+			expErr:   true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			_, err := destinations.NewXpub(test.key, test.template, nil)
+			is.Equal(test.expErr, err != nil)
+		})
+	}
+}
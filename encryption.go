@@ -0,0 +1,78 @@
+package dpp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PaymentSensitiveData are the Payment fields encrypted to the merchant when the
+// PaymentRequest publishes a PublicKey.
+type PaymentSensitiveData struct {
+	Memo       string      `json:"memo,omitempty"`
+	RefundTo   *string     `json:"refundTo,omitempty"`
+	Originator *Originator `json:"originator,omitempty"`
+}
+
+// Encrypt will move the Memo, RefundTo and Originator into Encrypted, ECIES encrypted
+// to the merchant public key so they cannot be read by proxies between wallet and host.
+func (p *Payment) Encrypt(pubKey *bec.PublicKey) error {
+	bb, err := json.Marshal(PaymentSensitiveData{
+		Memo:       p.Memo,
+		RefundTo:   p.RefundTo,
+		Originator: p.Originator,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode payment data")
+	}
+	enc, err := bec.Encrypt(pubKey, bb)
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt payment data")
+	}
+	p.Encrypted = base64.StdEncoding.EncodeToString(enc)
+	p.Memo, p.RefundTo, p.Originator = "", nil, nil
+	return nil
+}
+
+// Decrypt will, if the payment is Encrypted, decrypt it with the merchant key and
+// restore the Memo, RefundTo and Originator.
+func (p *Payment) Decrypt(key *bec.PrivateKey) error {
+	if p.Encrypted == "" {
+		return nil
+	}
+	enc, err := base64.StdEncoding.DecodeString(p.Encrypted)
+	if err != nil {
+		return validator.ErrValidation{"encrypted": []string{"value must be base64 encoded"}}
+	}
+	bb, err := bec.Decrypt(key, enc)
+	if err != nil {
+		return validator.ErrValidation{"encrypted": []string{"failed to decrypt, ensure it is encrypted to the payment request publicKey"}}
+	}
+	var data PaymentSensitiveData
+	if err := json.Unmarshal(bb, &data); err != nil {
+		return validator.ErrValidation{"encrypted": []string{"decrypted data is invalid"}}
+	}
+	p.Memo, p.RefundTo, p.Originator = data.Memo, data.RefundTo, data.Originator
+	p.Encrypted = ""
+	return nil
+}
+
+// EncryptionKey returns the PublicKey of the PaymentRequest, or nil if none is published.
+func (p PaymentRequest) EncryptionKey() (*bec.PublicKey, error) {
+	if p.PublicKey == "" {
+		return nil, nil
+	}
+	bb, err := hex.DecodeString(p.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid payment request publicKey")
+	}
+	pub, err := bec.ParsePubKey(bb, bec.S256())
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid payment request publicKey")
+	}
+	return pub, nil
+}
@@ -0,0 +1,77 @@
+package dpp_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestPayment_EncryptDecrypt(t *testing.T) {
+	is := is.New(t)
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	other, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	refundTo := "me@paymail.com"
+	p := dpp.Payment{
+		MerchantData: dpp.Beneficiary{PaymentReference: "abc123"},
+		Memo:         "for invoice 123",
+		RefundTo:     &refundTo,
+		Originator:   &dpp.Originator{Name: "payer", Paymail: "payer@paymail.com"},
+	}
+	is.NoErr(p.Encrypt(key.PubKey()))
+	is.True(p.Encrypted != "")
+	is.Equal(p.Memo, "")
+	is.True(p.RefundTo == nil)
+	is.True(p.Originator == nil)
+
+	wrong := p
+	err = wrong.Decrypt(other)
+	is.True(err != nil)
+	is.Equal(err.Error(), "[encrypted: failed to decrypt, ensure it is encrypted to the payment request publicKey]")
+
+	is.NoErr(p.Decrypt(key))
+	is.Equal(p.Encrypted, "")
+	is.Equal(p.Memo, "for invoice 123")
+	is.Equal(*p.RefundTo, refundTo)
+	is.Equal(p.Originator.Name, "payer")
+
+	// decrypting a cleartext payment is a no-op.
+	is.NoErr(p.Decrypt(key))
+	is.Equal(p.Memo, "for invoice 123")
+}
+
+func TestPayment_Validate_Encrypted(t *testing.T) {
+	is := is.New(t)
+	rawTx := "01000000000000000000"
+	p := dpp.Payment{
+		MerchantData: dpp.Beneficiary{PaymentReference: "abc123"},
+		RawTx:        &rawTx,
+		Encrypted:    "abc",
+		Memo:         "cleartext",
+	}
+	err := p.Validate()
+	is.True(err != nil)
+	is.Equal(err.Error(), "[encrypted: memo, refundTo and originator must be encrypted when encrypted is supplied]")
+}
+
+func TestPaymentRequest_EncryptionKey(t *testing.T) {
+	is := is.New(t)
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+
+	pub, err := dpp.PaymentRequest{}.EncryptionKey()
+	is.NoErr(err)
+	is.True(pub == nil)
+
+	pub, err = dpp.PaymentRequest{PublicKey: hex.EncodeToString(key.PubKey().SerialiseCompressed())}.EncryptionKey()
+	is.NoErr(err)
+	is.True(pub.IsEqual(key.PubKey()))
+
+	_, err = dpp.PaymentRequest{PublicKey: "zz"}.EncryptionKey()
+	is.True(err != nil)
+}
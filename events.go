@@ -0,0 +1,104 @@
+package dpp
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+)
+
+// EventType identifies a payment lifecycle event.
+type EventType string
+
+// Supported event types.
+const (
+	EventPaymentRequestServed EventType = "payment_request.served"
+	EventPaymentReceived      EventType = "payment.received"
+	EventPaymentRejected      EventType = "payment.rejected"
+	EventTransactionBroadcast EventType = "transaction.broadcast"
+	EventProofReceived        EventType = "proof.received"
+	EventInvoiceExpired       EventType = "invoice.expired"
+)
+
+// Event is a payment lifecycle event, subscribers can use a type switch
+// to get the concrete event, ie PaymentReceived.
+type Event interface {
+	EventType() EventType
+}
+
+// PaymentRequestServed is published when a PaymentRequest is returned to a payer.
+type PaymentRequestServed struct {
+	PaymentID      string         `json:"paymentId"`
+	PaymentRequest PaymentRequest `json:"paymentRequest"`
+	Timestamp      time.Time      `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (PaymentRequestServed) EventType() EventType { return EventPaymentRequestServed }
+
+// PaymentReceived is published when a payment is accepted for an invoice.
+type PaymentReceived struct {
+	PaymentID string     `json:"paymentId"`
+	TxID      string     `json:"txid"`
+	Payment   Payment    `json:"payment"`
+	ACK       PaymentACK `json:"ack"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (PaymentReceived) EventType() EventType { return EventPaymentReceived }
+
+// PaymentRejected is published when a payment is not accepted, either with an error
+// PaymentACK or because it could not be processed.
+type PaymentRejected struct {
+	PaymentID string `json:"paymentId"`
+	// Reason is the ACK memo or the error the payment failed with.
+	Reason string `json:"reason"`
+	// ACK is the PaymentACK returned, it is nil if the payment failed with an error.
+	ACK       *PaymentACK `json:"ack,omitempty"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (PaymentRejected) EventType() EventType { return EventPaymentRejected }
+
+// TransactionBroadcast is published when a payment transaction has been broadcast.
+type TransactionBroadcast struct {
+	PaymentID string    `json:"paymentId"`
+	TxID      string    `json:"txid"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (TransactionBroadcast) EventType() EventType { return EventTransactionBroadcast }
+
+// ProofReceived is published when a merkle proof is accepted for a transaction.
+type ProofReceived struct {
+	// PaymentID is the invoice the proof confirmed, it is empty if no reference was supplied.
+	PaymentID string                `json:"paymentId"`
+	TxID      string                `json:"txid"`
+	Proof     envelope.JSONEnvelope `json:"proof"`
+	Timestamp time.Time             `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (ProofReceived) EventType() EventType { return EventProofReceived }
+
+// InvoiceExpired is published when an invoice is marked as expired.
+type InvoiceExpired struct {
+	PaymentID string    `json:"paymentId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// EventType satisfies the Event interface.
+func (InvoiceExpired) EventType() EventType { return EventInvoiceExpired }
+
+// EventHandler handles a published event.
+type EventHandler func(ctx context.Context, e Event) error
+
+// EventPublisher publishes events to subscribers.
+type EventPublisher interface {
+	// Publish delivers the event to its subscribers, errors from synchronous subscribers are returned.
+	Publish(ctx context.Context, e Event) error
+}
@@ -0,0 +1,232 @@
+// Package events contains an in-process event bus that delivers dpp.Event values
+// published by the reference services to subscribers registered per event type.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// DefaultBuffer is the number of events queued for an asynchronous subscriber
+// before Publish blocks.
+const DefaultBuffer = 100
+
+// ErrClosed is returned when publishing to a closed Bus.
+var ErrClosed = errors.New("event bus is closed")
+
+type delivery struct {
+	ctx context.Context
+	e   dpp.Event
+}
+
+type subscription struct {
+	h      dpp.EventHandler
+	async  bool
+	buffer int
+	queue  chan delivery
+	// done is closed when the subscription is removed, publishers waiting on a full
+	// queue give up and the handler drains the events already queued.
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// close will stop the subscription accepting events, it is safe to call more than once.
+func (s *subscription) close() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// SubscribeOpt configures a subscription.
+type SubscribeOpt func(s *subscription)
+
+// WithAsync delivers events to the handler on its own goroutine, in the order they were
+// published, so Publish does not wait for it. Handler errors are passed to the bus error handler.
+func WithAsync() SubscribeOpt {
+	return func(s *subscription) {
+		s.async = true
+	}
+}
+
+// WithBuffer sets the number of events queued for an asynchronous handler, defaults to DefaultBuffer.
+func WithBuffer(n int) SubscribeOpt {
+	return func(s *subscription) {
+		s.buffer = n
+	}
+}
+
+// BusOpt configures a Bus.
+type BusOpt func(b *Bus)
+
+// WithErrorHandler sets the func called with errors returned by asynchronous handlers,
+// by default they are discarded.
+func WithErrorHandler(fn func(e dpp.Event, err error)) BusOpt {
+	return func(b *Bus) {
+		b.onError = fn
+	}
+}
+
+// Bus is an in-process dpp.EventPublisher.
+//
+// Synchronous handlers are called in the order they subscribed before Publish returns and
+// their errors are returned, asynchronous handlers each receive events on their own goroutine.
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[dpp.EventType][]*subscription
+	onError func(e dpp.Event, err error)
+	wg      sync.WaitGroup
+	closed  bool
+}
+
+// NewBus will setup and return a new Bus with no subscribers.
+func NewBus(opts ...BusOpt) *Bus {
+	b := &Bus{
+		subs:    map[dpp.EventType][]*subscription{},
+		onError: func(dpp.Event, error) {},
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	return b
+}
+
+// Subscribe will deliver events of type t to the handler, the returned func removes the subscription.
+func (b *Bus) Subscribe(t dpp.EventType, h dpp.EventHandler, opts ...SubscribeOpt) func() {
+	s := &subscription{h: h, buffer: DefaultBuffer}
+	for _, o := range opts {
+		o(s)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return func() {}
+	}
+	if s.async {
+		s.queue = make(chan delivery, s.buffer)
+		s.done = make(chan struct{})
+		b.wg.Add(1)
+		go b.run(s)
+	}
+	b.subs[t] = append(b.subs[t], s)
+	var once sync.Once
+	return func() {
+		once.Do(func() { b.unsubscribe(t, s) })
+	}
+}
+
+// Publish will deliver the event to its subscribers, it returns the first error from a
+// synchronous handler after every handler has been called. Handlers can publish further
+// events but must not subscribe or unsubscribe.
+//
+// Publish waits while an asynchronous subscribers queue is full, an asynchronous handler
+// publishing its own event type to its full queue waits until it is unsubscribed or the bus
+// is closed. Events published concurrently with Close may not be delivered.
+func (b *Bus) Publish(ctx context.Context, e dpp.Event) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	subs := append([]*subscription{}, b.subs[e.EventType()]...)
+	b.mu.RUnlock()
+	var first error
+	for _, s := range subs {
+		if s.async {
+			b.enqueue(s, delivery{ctx: detached{ctx}, e: e})
+			continue
+		}
+		if err := s.h(ctx, e); err != nil && first == nil {
+			first = errors.Wrapf(err, "%s handler failed", e.EventType())
+		}
+	}
+	return first
+}
+
+// enqueue will queue the delivery unless the subscription has been closed. The bus lock is not
+// held so a full queue does not block Subscribe, unsubscribe or Close.
+func (b *Bus) enqueue(s *subscription, d delivery) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+	select {
+	case s.queue <- d:
+	case <-s.done:
+	}
+}
+
+// Close will stop accepting events and wait for asynchronous handlers to
+// process the events already queued.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, ss := range b.subs {
+			for _, s := range ss {
+				if s.async {
+					s.close()
+				}
+			}
+		}
+		b.subs = map[dpp.EventType][]*subscription{}
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+func (b *Bus) unsubscribe(t dpp.EventType, s *subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ss := b.subs[t]
+	for i := range ss {
+		if ss[i] == s {
+			b.subs[t] = append(ss[:i:i], ss[i+1:]...)
+			if s.async {
+				s.close()
+			}
+			return
+		}
+	}
+}
+
+// run will deliver queued events to the handler until the subscription is closed, then
+// deliver the events still queued.
+func (b *Bus) run(s *subscription) {
+	defer b.wg.Done()
+	for {
+		select {
+		case d := <-s.queue:
+			b.handle(s, d)
+		case <-s.done:
+			for {
+				select {
+				case d := <-s.queue:
+					b.handle(s, d)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (b *Bus) handle(s *subscription, d delivery) {
+	if err := s.h(d.ctx, d.e); err != nil {
+		b.onError(d.e, err)
+	}
+}
+
+// detached keeps the values of a context but is not cancelled with it, asynchronous handlers
+// run after the publishing request has completed.
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detached) Done() <-chan struct{}               { return nil }
+func (detached) Err() error                          { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,120 @@
+package events_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/events"
+)
+
+func TestBus_Sync(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	bus := events.NewBus()
+	defer bus.Close()
+	var got []string
+	bus.Subscribe(dpp.EventPaymentReceived, func(ctx context.Context, e dpp.Event) error {
+		got = append(got, "first:"+e.(dpp.PaymentReceived).PaymentID)
+		return errors.New("boom")
+	})
+	unsubscribe := bus.Subscribe(dpp.EventPaymentReceived, func(ctx context.Context, e dpp.Event) error {
+		got = append(got, "second:"+e.(dpp.PaymentReceived).PaymentID)
+		return nil
+	})
+	bus.Subscribe(dpp.EventProofReceived, func(ctx context.Context, e dpp.Event) error {
+		got = append(got, "proof")
+		return nil
+	})
+
+	err := bus.Publish(ctx, dpp.PaymentReceived{PaymentID: "abc123"})
+	is.Equal(err.Error(), "payment.received handler failed: boom")
+	is.Equal(got, []string{"first:abc123", "second:abc123"})
+
+	unsubscribe()
+	unsubscribe()
+	got = nil
+	is.True(bus.Publish(ctx, dpp.PaymentReceived{PaymentID: "def456"}) != nil)
+	is.Equal(got, []string{"first:def456"})
+
+	// events without subscribers are dropped.
+	is.NoErr(bus.Publish(ctx, dpp.InvoiceExpired{PaymentID: "abc123"}))
+}
+
+func TestBus_Async(t *testing.T) {
+	is := is.New(t)
+	type key struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "value"))
+	var mu sync.Mutex
+	var failed []dpp.Event
+	bus := events.NewBus(events.WithErrorHandler(func(e dpp.Event, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		failed = append(failed, e)
+	}))
+	release := make(chan struct{})
+	var got []string
+	bus.Subscribe(dpp.EventTransactionBroadcast, func(ctx context.Context, e dpp.Event) error {
+		<-release
+		// the handler runs after the publisher context is cancelled but keeps its values.
+		is.NoErr(ctx.Err())
+		is.Equal(ctx.Value(key{}), "value")
+		got = append(got, e.(dpp.TransactionBroadcast).TxID)
+		if len(got) == 2 {
+			return errors.New("boom")
+		}
+		return nil
+	}, events.WithAsync(), events.WithBuffer(3))
+
+	start := time.Now()
+	for _, txID := range []string{"tx1", "tx2", "tx3"} {
+		is.NoErr(bus.Publish(ctx, dpp.TransactionBroadcast{TxID: txID}))
+	}
+	is.True(time.Since(start) < time.Second)
+	cancel()
+	close(release)
+	bus.Close()
+	is.Equal(got, []string{"tx1", "tx2", "tx3"})
+	is.Equal(len(failed), 1)
+	is.Equal(failed[0].(dpp.TransactionBroadcast).TxID, "tx2")
+
+	is.True(errors.Is(bus.Publish(context.Background(), dpp.TransactionBroadcast{}), events.ErrClosed))
+}
+
+func TestBus_AsyncFullQueue(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	bus := events.NewBus()
+	handled := make(chan struct{})
+	// the handler publishes its own type, filling its queue and waiting on it.
+	bus.Subscribe(dpp.EventTransactionBroadcast, func(ctx context.Context, e dpp.Event) error {
+		select {
+		case handled <- struct{}{}:
+		default:
+		}
+		return bus.Publish(ctx, e)
+	}, events.WithAsync(), events.WithBuffer(1))
+	is.NoErr(bus.Publish(ctx, dpp.TransactionBroadcast{TxID: "tx1"}))
+	<-handled
+
+	// a blocked publisher does not stall subscribing or closing the bus.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		unsubscribe := bus.Subscribe(dpp.EventProofReceived, func(ctx context.Context, e dpp.Event) error {
+			return nil
+		})
+		unsubscribe()
+		bus.Close()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("bus deadlocked")
+	}
+}
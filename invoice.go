@@ -0,0 +1,240 @@
+package dpp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// InvoiceState describes where an invoice is in its lifecycle.
+type InvoiceState string
+
+// Supported invoice states.
+const (
+	// InvoiceStateCreated is the initial state of an invoice, it can be paid.
+	InvoiceStateCreated InvoiceState = "created"
+	// InvoiceStatePending means a payment has been received and is being processed.
+	InvoiceStatePending InvoiceState = "pending"
+	// InvoiceStatePaid means the payment was accepted by the payment host.
+	InvoiceStatePaid InvoiceState = "paid"
+	// InvoiceStateBroadcast means the payment transaction has been broadcast to the network.
+	InvoiceStateBroadcast InvoiceState = "broadcast"
+	// InvoiceStateConfirmed means a merkle proof has been received for the payment transaction.
+	InvoiceStateConfirmed InvoiceState = "confirmed"
+	// InvoiceStateExpired means the invoice was not paid before its expiry time.
+	InvoiceStateExpired InvoiceState = "expired"
+	// InvoiceStateCancelled means the merchant withdrew the invoice before it was paid.
+	InvoiceStateCancelled InvoiceState = "cancelled"
+	// InvoiceStateRefunded means the payment has been returned to the payer.
+	InvoiceStateRefunded InvoiceState = "refunded"
+	// InvoiceStatePartiallyRefunded means part of the payment has been returned to the payer,
+	// further refunds can be made up to the amount paid. The invoice moves to confirmed if a
+	// proof arrives for the payment, the refunds made are still returned by the RefundReader.
+	InvoiceStatePartiallyRefunded InvoiceState = "partially_refunded"
+)
+
+// invoiceTransitions lists the states each state can move to.
+var invoiceTransitions = map[InvoiceState][]InvoiceState{
+	InvoiceStateCreated:           {InvoiceStatePending, InvoiceStateExpired, InvoiceStateCancelled},
+	InvoiceStatePending:           {InvoiceStatePaid, InvoiceStateCreated},
+	InvoiceStatePaid:              {InvoiceStateBroadcast, InvoiceStateRefunded, InvoiceStatePartiallyRefunded},
+	InvoiceStateBroadcast:         {InvoiceStateConfirmed, InvoiceStateRefunded, InvoiceStatePartiallyRefunded},
+	InvoiceStateConfirmed:         {InvoiceStateRefunded, InvoiceStatePartiallyRefunded},
+	InvoiceStatePartiallyRefunded: {InvoiceStateConfirmed, InvoiceStateRefunded, InvoiceStatePartiallyRefunded},
+}
+
+// CanTransition returns true if an invoice in state s can be moved to state to.
+func (s InvoiceState) CanTransition(to InvoiceState) bool {
+	for _, st := range invoiceTransitions[s] {
+		if st == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Terminal returns true if no further transitions are allowed from this state.
+func (s InvoiceState) Terminal() bool {
+	return len(invoiceTransitions[s]) == 0
+}
+
+// Invoice tracks the lifecycle of a PaymentRequest from creation through to
+// payment and confirmation.
+type Invoice struct {
+	// ID is the paymentID of the PaymentRequest this invoice represents.
+	ID string `json:"id"`
+	// State is the current state of the invoice.
+	State InvoiceState `json:"state" enums:"created,pending,paid,broadcast,confirmed,expired,cancelled,refunded,partially_refunded"`
+	// TxID is the id of the transaction that paid the invoice, empty until paid.
+	TxID string `json:"txid,omitempty"`
+	// ExpiresAt is the time after which the invoice can no longer be paid.
+	// A zero value means the invoice does not expire.
+	ExpiresAt time.Time `json:"expiresAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	// CreatedAt is the time the invoice was created.
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	// UpdatedAt is the time of the most recent transition.
+	UpdatedAt time.Time `json:"updatedAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	// History contains every transition the invoice has made, oldest first.
+	History []InvoiceStateChange `json:"history"`
+}
+
+// InvoiceStateChange records a single transition of an invoice.
+type InvoiceStateChange struct {
+	From      InvoiceState `json:"from"`
+	To        InvoiceState `json:"to"`
+	Timestamp time.Time    `json:"timestamp" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// NewInvoice will setup and return a new invoice in the created state.
+func NewInvoice(paymentID string, createdAt, expiresAt time.Time) *Invoice {
+	return &Invoice{
+		ID:        paymentID,
+		State:     InvoiceStateCreated,
+		ExpiresAt: expiresAt,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		History:   []InvoiceStateChange{},
+	}
+}
+
+// Expired returns true if the invoice has an expiry and it has passed at time t.
+func (i *Invoice) Expired(t time.Time) bool {
+	return !i.ExpiresAt.IsZero() && t.After(i.ExpiresAt)
+}
+
+// Transition moves the invoice to a new state at the time provided.
+//
+// If the move is not allowed from the current state an InvoiceTransitionError is returned.
+// If a payment is attempted after the invoice has expired an InvoiceExpiredError is returned.
+// In both cases the invoice is left unchanged.
+func (i *Invoice) Transition(to InvoiceState, at time.Time) error {
+	if !i.State.CanTransition(to) {
+		return InvoiceTransitionError{PaymentID: i.ID, From: i.State, To: to}
+	}
+	if to == InvoiceStatePending && i.Expired(at) {
+		return InvoiceExpiredError{PaymentID: i.ID, ExpiresAt: i.ExpiresAt}
+	}
+	i.History = append(i.History, InvoiceStateChange{
+		From:      i.State,
+		To:        to,
+		Timestamp: at,
+	})
+	i.State = to
+	i.UpdatedAt = at
+	return nil
+}
+
+// InvoiceTransitionError is returned when an invoice is asked to
+// move to a state that is not allowed from its current state.
+type InvoiceTransitionError struct {
+	PaymentID string
+	From      InvoiceState
+	To        InvoiceState
+}
+
+// Error satisfies the error interface.
+func (e InvoiceTransitionError) Error() string {
+	return fmt.Sprintf("invoice %s cannot move from %s to %s", e.PaymentID, e.From, e.To)
+}
+
+// Conflict indicates the request conflicts with the current state of the invoice.
+func (e InvoiceTransitionError) Conflict() bool {
+	return true
+}
+
+// InvoiceExpiredError is returned when a payment is attempted against an expired invoice.
+type InvoiceExpiredError struct {
+	PaymentID string
+	ExpiresAt time.Time
+}
+
+// Error satisfies the error interface.
+func (e InvoiceExpiredError) Error() string {
+	return fmt.Sprintf("invoice %s expired at %s", e.PaymentID, e.ExpiresAt.Format(time.RFC3339))
+}
+
+// Conflict indicates the request conflicts with the current state of the invoice.
+func (e InvoiceExpiredError) Conflict() bool {
+	return true
+}
+
+// ErrInvoiceNotFound is returned by stores when an invoice does not exist.
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+// InvoiceArgs identifies a single invoice.
+type InvoiceArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure that the InvoiceArgs are supplied and correct.
+func (i InvoiceArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(i.PaymentID)).
+		Err()
+}
+
+// InvoicesArgs are used to filter invoices.
+type InvoicesArgs struct {
+	// From returns invoices created at or after this time.
+	From time.Time `query:"from"`
+	// To returns invoices created before this time.
+	To time.Time `query:"to"`
+}
+
+// Validate will ensure that the InvoicesArgs are correct.
+func (i InvoicesArgs) Validate() error {
+	v := validator.New()
+	if !i.From.IsZero() && !i.To.IsZero() {
+		v = v.Validate("to", validator.DateAfter(i.To, i.From))
+	}
+	return v.Err()
+}
+
+// InvoiceUpdate is used to move an invoice to a new state.
+type InvoiceUpdate struct {
+	// State is the state to move to.
+	State InvoiceState
+	// TxID if set will be recorded against the invoice.
+	TxID string
+	// Timestamp is the time the transition occurred.
+	Timestamp time.Time
+	// Outbox are webhooks to queue with the transition, stores must write them in the same
+	// transaction so they are only queued if the transition succeeds.
+	Outbox []OutboxMessage
+}
+
+// InvoiceReader will read invoices from a data store.
+type InvoiceReader interface {
+	// Invoice will return an invoice by its paymentID or ErrInvoiceNotFound.
+	Invoice(ctx context.Context, args InvoiceArgs) (*Invoice, error)
+	// Invoices will return all invoices matching the args, oldest first.
+	Invoices(ctx context.Context, args InvoicesArgs) ([]Invoice, error)
+}
+
+// InvoiceWriter will write invoices to a data store.
+type InvoiceWriter interface {
+	// InvoiceCreate will persist a new invoice.
+	InvoiceCreate(ctx context.Context, req Invoice) (*Invoice, error)
+	// InvoiceUpdate will apply Invoice.Transition to the stored invoice.
+	// This must be atomic so concurrent payments cannot both move an invoice
+	// out of the same state, errors from Transition should be returned unaltered.
+	InvoiceUpdate(ctx context.Context, args InvoiceArgs, req InvoiceUpdate) (*Invoice, error)
+}
+
+// InvoiceReaderWriter combines the reader and writer interfaces.
+type InvoiceReaderWriter interface {
+	InvoiceReader
+	InvoiceWriter
+}
+
+// InvoiceExpiryService expires invoices that were not paid before their expiry time. Payments
+// against an expired invoice expire it as they are rejected, the service should be run
+// periodically so unpaid invoices are expired as well.
+type InvoiceExpiryService interface {
+	// InvoicesExpire will mark every created invoice whose expiry has passed at as expired,
+	// returning the invoices expired.
+	InvoicesExpire(ctx context.Context, at time.Time) ([]Invoice, error)
+}
@@ -0,0 +1,129 @@
+package dpp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+)
+
+func TestInvoice_Transition(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		inv    *Invoice
+		to     InvoiceState
+		at     time.Time
+		expErr error
+		exp    InvoiceState
+	}{
+		"created invoice can move to pending": {
+			inv: NewInvoice("abc123", now, now.Add(time.Hour)),
+			to:  InvoiceStatePending,
+			at:  now.Add(time.Minute),
+			exp: InvoiceStatePending,
+		}, "invoice without expiry can move to pending at any time": {
+			inv: NewInvoice("abc123", now, time.Time{}),
+			to:  InvoiceStatePending,
+			at:  now.Add(time.Hour * 24 * 365),
+			exp: InvoiceStatePending,
+		}, "expired invoice cannot move to pending": {
+			inv:    NewInvoice("abc123", now, now.Add(time.Hour)),
+			to:     InvoiceStatePending,
+			at:     now.Add(time.Hour * 2),
+			expErr: InvoiceExpiredError{PaymentID: "abc123", ExpiresAt: now.Add(time.Hour)},
+			exp:    InvoiceStateCreated,
+		}, "created invoice cannot move to paid": {
+			inv:    NewInvoice("abc123", now, now.Add(time.Hour)),
+			to:     InvoiceStatePaid,
+			at:     now,
+			expErr: InvoiceTransitionError{PaymentID: "abc123", From: InvoiceStateCreated, To: InvoiceStatePaid},
+			exp:    InvoiceStateCreated,
+		}, "paid invoice cannot be paid again": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, now.Add(time.Hour))
+				inv.State = InvoiceStatePaid
+				return inv
+			}(),
+			to:     InvoiceStatePending,
+			at:     now,
+			expErr: InvoiceTransitionError{PaymentID: "abc123", From: InvoiceStatePaid, To: InvoiceStatePending},
+			exp:    InvoiceStatePaid,
+		}, "cancelled invoice is terminal": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, now.Add(time.Hour))
+				inv.State = InvoiceStateCancelled
+				return inv
+			}(),
+			to:     InvoiceStatePending,
+			at:     now,
+			expErr: InvoiceTransitionError{PaymentID: "abc123", From: InvoiceStateCancelled, To: InvoiceStatePending},
+			exp:    InvoiceStateCancelled,
+		}, "broadcast invoice can be confirmed": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, now.Add(time.Hour))
+				inv.State = InvoiceStateBroadcast
+				return inv
+			}(),
+			to:  InvoiceStateConfirmed,
+			at:  now.Add(time.Hour * 3),
+			exp: InvoiceStateConfirmed,
+		}, "partially refunded invoice can be partially refunded again": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, time.Time{})
+				inv.State = InvoiceStatePartiallyRefunded
+				return inv
+			}(),
+			to:  InvoiceStatePartiallyRefunded,
+			at:  now,
+			exp: InvoiceStatePartiallyRefunded,
+		}, "partially refunded invoice can be confirmed": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, time.Time{})
+				inv.State = InvoiceStatePartiallyRefunded
+				return inv
+			}(),
+			to:  InvoiceStateConfirmed,
+			at:  now,
+			exp: InvoiceStateConfirmed,
+		}, "refunded invoice cannot be confirmed": {
+			inv: func() *Invoice {
+				inv := NewInvoice("abc123", now, time.Time{})
+				inv.State = InvoiceStateRefunded
+				return inv
+			}(),
+			to:     InvoiceStateConfirmed,
+			at:     now,
+			expErr: InvoiceTransitionError{PaymentID: "abc123", From: InvoiceStateRefunded, To: InvoiceStateConfirmed},
+			exp:    InvoiceStateRefunded,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			err := test.inv.Transition(test.to, test.at)
+			is.Equal(test.exp, test.inv.State)
+			if test.expErr != nil {
+				is.True(err != nil)
+				is.Equal(test.expErr, err)
+				is.Equal(len(test.inv.History), 0)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(len(test.inv.History), 1)
+			is.Equal(test.inv.History[0].To, test.to)
+			is.Equal(test.inv.History[0].Timestamp, test.at)
+			is.Equal(test.inv.UpdatedAt, test.at)
+		})
+	}
+}
+
+func TestInvoice_TransitionErrorsAreTyped(t *testing.T) {
+	is := is.New(t)
+	inv := NewInvoice("abc123", time.Now(), time.Time{})
+	err := errors.Wrap(inv.Transition(InvoiceStateConfirmed, time.Now()), "wrapped")
+	var errT InvoiceTransitionError
+	is.True(errors.As(err, &errT))
+	is.Equal(errT.From, InvoiceStateCreated)
+	is.Equal(errT.To, InvoiceStateConfirmed)
+}
@@ -0,0 +1,88 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/libsv/go-dpp"
+	"sync"
+)
+
+// Ensure, that DestinationsCreatorMock does implement dpp.DestinationsCreator.
+// If this is not the case, regenerate this file with moq.
+var _ dpp.DestinationsCreator = &DestinationsCreatorMock{}
+
+// DestinationsCreatorMock is a mock implementation of dpp.DestinationsCreator.
+//
+//	func TestSomethingThatUsesDestinationsCreator(t *testing.T) {
+//
+//		// make and configure a mocked dpp.DestinationsCreator
+//		mockedDestinationsCreator := &DestinationsCreatorMock{
+//			DestinationsCreateFunc: func(ctx context.Context, args dpp.DestinationsArgs, req dpp.DestinationsCreate) (*dpp.PaymentDestinations, error) {
+//				panic("mock out the DestinationsCreate method")
+//			},
+//		}
+//
+//		// use mockedDestinationsCreator in code that requires dpp.DestinationsCreator
+//		// and then make assertions.
+//
+//	}
+type DestinationsCreatorMock struct {
+	// DestinationsCreateFunc mocks the DestinationsCreate method.
+	DestinationsCreateFunc func(ctx context.Context, args dpp.DestinationsArgs, req dpp.DestinationsCreate) (*dpp.PaymentDestinations, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// DestinationsCreate holds details about calls to the DestinationsCreate method.
+		DestinationsCreate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.DestinationsArgs
+			// Req is the req argument value.
+			Req dpp.DestinationsCreate
+		}
+	}
+	lockDestinationsCreate sync.RWMutex
+}
+
+// DestinationsCreate calls DestinationsCreateFunc.
+func (mock *DestinationsCreatorMock) DestinationsCreate(ctx context.Context, args dpp.DestinationsArgs, req dpp.DestinationsCreate) (*dpp.PaymentDestinations, error) {
+	if mock.DestinationsCreateFunc == nil {
+		panic("DestinationsCreatorMock.DestinationsCreateFunc: method is nil but DestinationsCreator.DestinationsCreate was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.DestinationsArgs
+		Req  dpp.DestinationsCreate
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockDestinationsCreate.Lock()
+	mock.calls.DestinationsCreate = append(mock.calls.DestinationsCreate, callInfo)
+	mock.lockDestinationsCreate.Unlock()
+	return mock.DestinationsCreateFunc(ctx, args, req)
+}
+
+// DestinationsCreateCalls gets all the calls that were made to DestinationsCreate.
+// Check the length with:
+//
+//	len(mockedDestinationsCreator.DestinationsCreateCalls())
+func (mock *DestinationsCreatorMock) DestinationsCreateCalls() []struct {
+	Ctx  context.Context
+	Args dpp.DestinationsArgs
+	Req  dpp.DestinationsCreate
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.DestinationsArgs
+		Req  dpp.DestinationsCreate
+	}
+	mock.lockDestinationsCreate.RLock()
+	calls = mock.calls.DestinationsCreate
+	mock.lockDestinationsCreate.RUnlock()
+	return calls
+}
@@ -0,0 +1,138 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/libsv/go-dpp"
+	"sync"
+)
+
+// Ensure, that InvoiceWriterMock does implement dpp.InvoiceWriter.
+// If this is not the case, regenerate this file with moq.
+var _ dpp.InvoiceWriter = &InvoiceWriterMock{}
+
+// InvoiceWriterMock is a mock implementation of dpp.InvoiceWriter.
+//
+//	func TestSomethingThatUsesInvoiceWriter(t *testing.T) {
+//
+//		// make and configure a mocked dpp.InvoiceWriter
+//		mockedInvoiceWriter := &InvoiceWriterMock{
+//			InvoiceCreateFunc: func(ctx context.Context, req dpp.Invoice) (*dpp.Invoice, error) {
+//				panic("mock out the InvoiceCreate method")
+//			},
+//			InvoiceUpdateFunc: func(ctx context.Context, args dpp.InvoiceArgs, req dpp.InvoiceUpdate) (*dpp.Invoice, error) {
+//				panic("mock out the InvoiceUpdate method")
+//			},
+//		}
+//
+//		// use mockedInvoiceWriter in code that requires dpp.InvoiceWriter
+//		// and then make assertions.
+//
+//	}
+type InvoiceWriterMock struct {
+	// InvoiceCreateFunc mocks the InvoiceCreate method.
+	InvoiceCreateFunc func(ctx context.Context, req dpp.Invoice) (*dpp.Invoice, error)
+
+	// InvoiceUpdateFunc mocks the InvoiceUpdate method.
+	InvoiceUpdateFunc func(ctx context.Context, args dpp.InvoiceArgs, req dpp.InvoiceUpdate) (*dpp.Invoice, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// InvoiceCreate holds details about calls to the InvoiceCreate method.
+		InvoiceCreate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Req is the req argument value.
+			Req dpp.Invoice
+		}
+		// InvoiceUpdate holds details about calls to the InvoiceUpdate method.
+		InvoiceUpdate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.InvoiceArgs
+			// Req is the req argument value.
+			Req dpp.InvoiceUpdate
+		}
+	}
+	lockInvoiceCreate sync.RWMutex
+	lockInvoiceUpdate sync.RWMutex
+}
+
+// InvoiceCreate calls InvoiceCreateFunc.
+func (mock *InvoiceWriterMock) InvoiceCreate(ctx context.Context, req dpp.Invoice) (*dpp.Invoice, error) {
+	if mock.InvoiceCreateFunc == nil {
+		panic("InvoiceWriterMock.InvoiceCreateFunc: method is nil but InvoiceWriter.InvoiceCreate was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Req dpp.Invoice
+	}{
+		Ctx: ctx,
+		Req: req,
+	}
+	mock.lockInvoiceCreate.Lock()
+	mock.calls.InvoiceCreate = append(mock.calls.InvoiceCreate, callInfo)
+	mock.lockInvoiceCreate.Unlock()
+	return mock.InvoiceCreateFunc(ctx, req)
+}
+
+// InvoiceCreateCalls gets all the calls that were made to InvoiceCreate.
+// Check the length with:
+//
+//	len(mockedInvoiceWriter.InvoiceCreateCalls())
+func (mock *InvoiceWriterMock) InvoiceCreateCalls() []struct {
+	Ctx context.Context
+	Req dpp.Invoice
+} {
+	var calls []struct {
+		Ctx context.Context
+		Req dpp.Invoice
+	}
+	mock.lockInvoiceCreate.RLock()
+	calls = mock.calls.InvoiceCreate
+	mock.lockInvoiceCreate.RUnlock()
+	return calls
+}
+
+// InvoiceUpdate calls InvoiceUpdateFunc.
+func (mock *InvoiceWriterMock) InvoiceUpdate(ctx context.Context, args dpp.InvoiceArgs, req dpp.InvoiceUpdate) (*dpp.Invoice, error) {
+	if mock.InvoiceUpdateFunc == nil {
+		panic("InvoiceWriterMock.InvoiceUpdateFunc: method is nil but InvoiceWriter.InvoiceUpdate was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.InvoiceArgs
+		Req  dpp.InvoiceUpdate
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockInvoiceUpdate.Lock()
+	mock.calls.InvoiceUpdate = append(mock.calls.InvoiceUpdate, callInfo)
+	mock.lockInvoiceUpdate.Unlock()
+	return mock.InvoiceUpdateFunc(ctx, args, req)
+}
+
+// InvoiceUpdateCalls gets all the calls that were made to InvoiceUpdate.
+// Check the length with:
+//
+//	len(mockedInvoiceWriter.InvoiceUpdateCalls())
+func (mock *InvoiceWriterMock) InvoiceUpdateCalls() []struct {
+	Ctx  context.Context
+	Args dpp.InvoiceArgs
+	Req  dpp.InvoiceUpdate
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.InvoiceArgs
+		Req  dpp.InvoiceUpdate
+	}
+	mock.lockInvoiceUpdate.RLock()
+	calls = mock.calls.InvoiceUpdate
+	mock.lockInvoiceUpdate.RUnlock()
+	return calls
+}
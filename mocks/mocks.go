@@ -3,3 +3,8 @@ package mocks
 //go:generate moq -pkg mocks -out payment_writer.go ../ PaymentWriter
 //go:generate moq -pkg mocks -out payment_service.go ../ PaymentService
 //go:generate moq -pkg mocks -out payment_request_service.go ../ PaymentRequestService
+//go:generate moq -pkg mocks -out invoice_writer.go ../ InvoiceWriter
+//go:generate moq -pkg mocks -out proofs_writer.go ../ ProofsWriter
+//go:generate moq -pkg mocks -out proofs_service.go ../ ProofsService
+//go:generate moq -pkg mocks -out payment_request_reader_writer.go ../ PaymentRequestReaderWriter
+//go:generate moq -pkg mocks -out destinations_creator.go ../ DestinationsCreator
@@ -0,0 +1,138 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/libsv/go-dpp"
+	"sync"
+)
+
+// Ensure, that PaymentRequestReaderWriterMock does implement dpp.PaymentRequestReaderWriter.
+// If this is not the case, regenerate this file with moq.
+var _ dpp.PaymentRequestReaderWriter = &PaymentRequestReaderWriterMock{}
+
+// PaymentRequestReaderWriterMock is a mock implementation of dpp.PaymentRequestReaderWriter.
+//
+//	func TestSomethingThatUsesPaymentRequestReaderWriter(t *testing.T) {
+//
+//		// make and configure a mocked dpp.PaymentRequestReaderWriter
+//		mockedPaymentRequestReaderWriter := &PaymentRequestReaderWriterMock{
+//			PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+//				panic("mock out the PaymentRequest method")
+//			},
+//			PaymentRequestCreateFunc: func(ctx context.Context, args dpp.PaymentRequestArgs, req dpp.PaymentRequest) (*dpp.PaymentRequest, error) {
+//				panic("mock out the PaymentRequestCreate method")
+//			},
+//		}
+//
+//		// use mockedPaymentRequestReaderWriter in code that requires dpp.PaymentRequestReaderWriter
+//		// and then make assertions.
+//
+//	}
+type PaymentRequestReaderWriterMock struct {
+	// PaymentRequestFunc mocks the PaymentRequest method.
+	PaymentRequestFunc func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error)
+
+	// PaymentRequestCreateFunc mocks the PaymentRequestCreate method.
+	PaymentRequestCreateFunc func(ctx context.Context, args dpp.PaymentRequestArgs, req dpp.PaymentRequest) (*dpp.PaymentRequest, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// PaymentRequest holds details about calls to the PaymentRequest method.
+		PaymentRequest []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.PaymentRequestArgs
+		}
+		// PaymentRequestCreate holds details about calls to the PaymentRequestCreate method.
+		PaymentRequestCreate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.PaymentRequestArgs
+			// Req is the req argument value.
+			Req dpp.PaymentRequest
+		}
+	}
+	lockPaymentRequest       sync.RWMutex
+	lockPaymentRequestCreate sync.RWMutex
+}
+
+// PaymentRequest calls PaymentRequestFunc.
+func (mock *PaymentRequestReaderWriterMock) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	if mock.PaymentRequestFunc == nil {
+		panic("PaymentRequestReaderWriterMock.PaymentRequestFunc: method is nil but PaymentRequestReaderWriter.PaymentRequest was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.PaymentRequestArgs
+	}{
+		Ctx:  ctx,
+		Args: args,
+	}
+	mock.lockPaymentRequest.Lock()
+	mock.calls.PaymentRequest = append(mock.calls.PaymentRequest, callInfo)
+	mock.lockPaymentRequest.Unlock()
+	return mock.PaymentRequestFunc(ctx, args)
+}
+
+// PaymentRequestCalls gets all the calls that were made to PaymentRequest.
+// Check the length with:
+//
+//	len(mockedPaymentRequestReaderWriter.PaymentRequestCalls())
+func (mock *PaymentRequestReaderWriterMock) PaymentRequestCalls() []struct {
+	Ctx  context.Context
+	Args dpp.PaymentRequestArgs
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.PaymentRequestArgs
+	}
+	mock.lockPaymentRequest.RLock()
+	calls = mock.calls.PaymentRequest
+	mock.lockPaymentRequest.RUnlock()
+	return calls
+}
+
+// PaymentRequestCreate calls PaymentRequestCreateFunc.
+func (mock *PaymentRequestReaderWriterMock) PaymentRequestCreate(ctx context.Context, args dpp.PaymentRequestArgs, req dpp.PaymentRequest) (*dpp.PaymentRequest, error) {
+	if mock.PaymentRequestCreateFunc == nil {
+		panic("PaymentRequestReaderWriterMock.PaymentRequestCreateFunc: method is nil but PaymentRequestReaderWriter.PaymentRequestCreate was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.PaymentRequestArgs
+		Req  dpp.PaymentRequest
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockPaymentRequestCreate.Lock()
+	mock.calls.PaymentRequestCreate = append(mock.calls.PaymentRequestCreate, callInfo)
+	mock.lockPaymentRequestCreate.Unlock()
+	return mock.PaymentRequestCreateFunc(ctx, args, req)
+}
+
+// PaymentRequestCreateCalls gets all the calls that were made to PaymentRequestCreate.
+// Check the length with:
+//
+//	len(mockedPaymentRequestReaderWriter.PaymentRequestCreateCalls())
+func (mock *PaymentRequestReaderWriterMock) PaymentRequestCreateCalls() []struct {
+	Ctx  context.Context
+	Args dpp.PaymentRequestArgs
+	Req  dpp.PaymentRequest
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.PaymentRequestArgs
+		Req  dpp.PaymentRequest
+	}
+	mock.lockPaymentRequestCreate.RLock()
+	calls = mock.calls.PaymentRequestCreate
+	mock.lockPaymentRequestCreate.RUnlock()
+	return calls
+}
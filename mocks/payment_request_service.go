@@ -15,19 +15,19 @@ var _ dpp.PaymentRequestService = &PaymentRequestServiceMock{}
 
 // PaymentRequestServiceMock is a mock implementation of dpp.PaymentRequestService.
 //
-// 	func TestSomethingThatUsesPaymentRequestService(t *testing.T) {
+//	func TestSomethingThatUsesPaymentRequestService(t *testing.T) {
 //
-// 		// make and configure a mocked dpp.PaymentRequestService
-// 		mockedPaymentRequestService := &PaymentRequestServiceMock{
-// 			PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
-// 				panic("mock out the PaymentRequest method")
-// 			},
-// 		}
+//		// make and configure a mocked dpp.PaymentRequestService
+//		mockedPaymentRequestService := &PaymentRequestServiceMock{
+//			PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+//				panic("mock out the PaymentRequest method")
+//			},
+//		}
 //
-// 		// use mockedPaymentRequestService in code that requires dpp.PaymentRequestService
-// 		// and then make assertions.
+//		// use mockedPaymentRequestService in code that requires dpp.PaymentRequestService
+//		// and then make assertions.
 //
-// 	}
+//	}
 type PaymentRequestServiceMock struct {
 	// PaymentRequestFunc mocks the PaymentRequest method.
 	PaymentRequestFunc func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error)
@@ -65,7 +65,8 @@ func (mock *PaymentRequestServiceMock) PaymentRequest(ctx context.Context, args
 
 // PaymentRequestCalls gets all the calls that were made to PaymentRequest.
 // Check the length with:
-//     len(mockedPaymentRequestService.PaymentRequestCalls())
+//
+//	len(mockedPaymentRequestService.PaymentRequestCalls())
 func (mock *PaymentRequestServiceMock) PaymentRequestCalls() []struct {
 	Ctx  context.Context
 	Args dpp.PaymentRequestArgs
@@ -0,0 +1,89 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"sync"
+)
+
+// Ensure, that ProofsServiceMock does implement dpp.ProofsService.
+// If this is not the case, regenerate this file with moq.
+var _ dpp.ProofsService = &ProofsServiceMock{}
+
+// ProofsServiceMock is a mock implementation of dpp.ProofsService.
+//
+//	func TestSomethingThatUsesProofsService(t *testing.T) {
+//
+//		// make and configure a mocked dpp.ProofsService
+//		mockedProofsService := &ProofsServiceMock{
+//			CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+//				panic("mock out the Create method")
+//			},
+//		}
+//
+//		// use mockedProofsService in code that requires dpp.ProofsService
+//		// and then make assertions.
+//
+//	}
+type ProofsServiceMock struct {
+	// CreateFunc mocks the Create method.
+	CreateFunc func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Create holds details about calls to the Create method.
+		Create []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.ProofCreateArgs
+			// Req is the req argument value.
+			Req envelope.JSONEnvelope
+		}
+	}
+	lockCreate sync.RWMutex
+}
+
+// Create calls CreateFunc.
+func (mock *ProofsServiceMock) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	if mock.CreateFunc == nil {
+		panic("ProofsServiceMock.CreateFunc: method is nil but ProofsService.Create was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.ProofCreateArgs
+		Req  envelope.JSONEnvelope
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockCreate.Lock()
+	mock.calls.Create = append(mock.calls.Create, callInfo)
+	mock.lockCreate.Unlock()
+	return mock.CreateFunc(ctx, args, req)
+}
+
+// CreateCalls gets all the calls that were made to Create.
+// Check the length with:
+//
+//	len(mockedProofsService.CreateCalls())
+func (mock *ProofsServiceMock) CreateCalls() []struct {
+	Ctx  context.Context
+	Args dpp.ProofCreateArgs
+	Req  envelope.JSONEnvelope
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.ProofCreateArgs
+		Req  envelope.JSONEnvelope
+	}
+	mock.lockCreate.RLock()
+	calls = mock.calls.Create
+	mock.lockCreate.RUnlock()
+	return calls
+}
@@ -0,0 +1,89 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"sync"
+)
+
+// Ensure, that ProofsWriterMock does implement dpp.ProofsWriter.
+// If this is not the case, regenerate this file with moq.
+var _ dpp.ProofsWriter = &ProofsWriterMock{}
+
+// ProofsWriterMock is a mock implementation of dpp.ProofsWriter.
+//
+//	func TestSomethingThatUsesProofsWriter(t *testing.T) {
+//
+//		// make and configure a mocked dpp.ProofsWriter
+//		mockedProofsWriter := &ProofsWriterMock{
+//			ProofCreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+//				panic("mock out the ProofCreate method")
+//			},
+//		}
+//
+//		// use mockedProofsWriter in code that requires dpp.ProofsWriter
+//		// and then make assertions.
+//
+//	}
+type ProofsWriterMock struct {
+	// ProofCreateFunc mocks the ProofCreate method.
+	ProofCreateFunc func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// ProofCreate holds details about calls to the ProofCreate method.
+		ProofCreate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Args is the args argument value.
+			Args dpp.ProofCreateArgs
+			// Req is the req argument value.
+			Req envelope.JSONEnvelope
+		}
+	}
+	lockProofCreate sync.RWMutex
+}
+
+// ProofCreate calls ProofCreateFunc.
+func (mock *ProofsWriterMock) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	if mock.ProofCreateFunc == nil {
+		panic("ProofsWriterMock.ProofCreateFunc: method is nil but ProofsWriter.ProofCreate was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		Args dpp.ProofCreateArgs
+		Req  envelope.JSONEnvelope
+	}{
+		Ctx:  ctx,
+		Args: args,
+		Req:  req,
+	}
+	mock.lockProofCreate.Lock()
+	mock.calls.ProofCreate = append(mock.calls.ProofCreate, callInfo)
+	mock.lockProofCreate.Unlock()
+	return mock.ProofCreateFunc(ctx, args, req)
+}
+
+// ProofCreateCalls gets all the calls that were made to ProofCreate.
+// Check the length with:
+//
+//	len(mockedProofsWriter.ProofCreateCalls())
+func (mock *ProofsWriterMock) ProofCreateCalls() []struct {
+	Ctx  context.Context
+	Args dpp.ProofCreateArgs
+	Req  envelope.JSONEnvelope
+} {
+	var calls []struct {
+		Ctx  context.Context
+		Args dpp.ProofCreateArgs
+		Req  envelope.JSONEnvelope
+	}
+	mock.lockProofCreate.RLock()
+	calls = mock.calls.ProofCreate
+	mock.lockProofCreate.RUnlock()
+	return calls
+}
@@ -0,0 +1,68 @@
+package dpp
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// paymailRegex matches an alias@domain.tld paymail, aliases can contain letters, digits
+// and . _ - + and the domain must contain at least one dot.
+var paymailRegex = regexp.MustCompile(`^[a-zA-Z0-9._+-]+@([a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`)
+
+// ValidatePaymail will check the value is a syntactically valid paymail handle.
+func ValidatePaymail(paymail string) validator.ValidationFunc {
+	return func() error {
+		if !paymailRegex.MatchString(paymail) {
+			return errors.New("value must be a valid paymail in the format alias@domain.tld")
+		}
+		return nil
+	}
+}
+
+// SplitPaymail returns the lower cased alias and domain of a paymail.
+func SplitPaymail(paymail string) (alias, domain string, err error) {
+	if err := ValidatePaymail(paymail)(); err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.ToLower(paymail), "@", 2)
+	return parts[0], parts[1], nil
+}
+
+// ErrPaymailNotFound is returned by a PaymailResolver when the paymail does not exist.
+var ErrPaymailNotFound = errors.New("paymail not found")
+
+// PaymailDestinationArgs identify the paymail and amount to resolve.
+type PaymailDestinationArgs struct {
+	// Paymail is the handle to pay, ie Payment.RefundTo.
+	Paymail string
+	// Satoshis is the total amount that will be paid.
+	Satoshis uint64
+}
+
+// Validate will ensure the PaymailDestinationArgs are correct.
+func (p PaymailDestinationArgs) Validate() error {
+	return validator.New().
+		Validate("paymail", ValidatePaymail(p.Paymail)).
+		Validate("satoshis", validator.PositiveUInt64(p.Satoshis)).
+		Err()
+}
+
+// PaymailDestination is where to pay a paymail.
+type PaymailDestination struct {
+	// Outputs are the locking scripts and amounts to pay. A P2P payment destination can
+	// return several, a basic address resolution returns one for the full amount.
+	Outputs []Output `json:"outputs"`
+	// Reference is the P2P reference to send with the transaction, it is
+	// empty when the destination came from basic address resolution.
+	Reference string `json:"reference,omitempty"`
+}
+
+// PaymailResolver will look up where to pay a paymail.
+type PaymailResolver interface {
+	// PaymailDestination returns the outputs to pay or ErrPaymailNotFound.
+	PaymailDestination(ctx context.Context, args PaymailDestinationArgs) (*PaymailDestination, error)
+}
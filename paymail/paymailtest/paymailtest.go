@@ -0,0 +1,62 @@
+// Package paymailtest provides an in-process fake dpp.PaymailResolver for use in tests.
+package paymailtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Resolver is a fake dpp.PaymailResolver that pays the full amount to the
+// locking script added for a paymail, paymails are matched case insensitively.
+type Resolver struct {
+	mu      sync.RWMutex
+	scripts map[string]*bscript.Script
+	refs    int
+	calls   []dpp.PaymailDestinationArgs
+}
+
+// NewResolver will return a new fake resolver with no paymails.
+func NewResolver() *Resolver {
+	return &Resolver{scripts: map[string]*bscript.Script{}}
+}
+
+// Add will make the paymail resolvable, paying to the locking script.
+func (r *Resolver) Add(paymail string, script *bscript.Script) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scripts[strings.ToLower(paymail)] = script
+}
+
+// PaymailDestination will return a single output paying the full amount to the
+// paymails locking script along with a unique reference.
+func (r *Resolver) PaymailDestination(ctx context.Context, args dpp.PaymailDestinationArgs) (*dpp.PaymailDestination, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, args)
+	script, ok := r.scripts[strings.ToLower(args.Paymail)]
+	if !ok {
+		return nil, errors.Wrapf(dpp.ErrPaymailNotFound, "paymail %s", args.Paymail)
+	}
+	r.refs++
+	return &dpp.PaymailDestination{
+		Outputs:   []dpp.Output{{Amount: args.Satoshis, LockingScript: script}},
+		Reference: fmt.Sprintf("ref%d", r.refs),
+	}, nil
+}
+
+// Calls returns the args of every PaymailDestination call.
+func (r *Resolver) Calls() []dpp.PaymailDestinationArgs {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]dpp.PaymailDestinationArgs{}, r.calls...)
+}
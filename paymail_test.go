@@ -0,0 +1,68 @@
+package dpp_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestValidatePaymail(t *testing.T) {
+	tests := map[string]struct {
+		paymail string
+		valid   bool
+	}{
+		"simple paymail should be valid": {
+			paymail: "me@paymail.com",
+			valid:   true,
+		}, "alias with punctuation should be valid": {
+			paymail: "first.last+refunds@pay-mail.co.uk",
+			valid:   true,
+		}, "missing alias should be invalid": {
+			paymail: "@paymail.com",
+		}, "missing domain should be invalid": {
+			paymail: "me@",
+		}, "domain without tld should be invalid": {
+			paymail: "me@localhost",
+		}, "two at signs should be invalid": {
+			paymail: "me@you@paymail.com",
+		}, "spaces should be invalid": {
+			paymail: "me @paymail.com",
+		}, "handcash handle should be invalid": {
+			paymail: "$me",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			err := dpp.ValidatePaymail(test.paymail)()
+			is.Equal(err == nil, test.valid)
+		})
+	}
+}
+
+func TestPayment_Validate_RefundTo(t *testing.T) {
+	is := is.New(t)
+	rawTx := "01000000000000000000"
+	refundTo := "not a paymail"
+	p := dpp.Payment{
+		MerchantData: dpp.Beneficiary{PaymentReference: "abc123"},
+		RawTx:        &rawTx,
+		RefundTo:     &refundTo,
+	}
+	err := p.Validate()
+	is.True(err != nil)
+	is.Equal(err.Error(), "[refundTo: value must be a valid paymail in the format alias@domain.tld]")
+
+	refundTo = "Me@Paymail.com"
+	is.NoErr(p.Validate())
+	alias, domain, err := dpp.SplitPaymail(refundTo)
+	is.NoErr(err)
+	is.Equal(alias, "me")
+	is.Equal(domain, "paymail.com")
+
+	// an empty refundTo means no refund paymail was supplied.
+	refundTo = ""
+	is.NoErr(p.Validate())
+}
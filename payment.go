@@ -2,7 +2,10 @@ package dpp
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/libsv/go-bk/envelope"
 	"github.com/libsv/go-bt/v2"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
@@ -13,11 +16,14 @@ import (
 type Payment struct {
 	// MerchantData is copied from PaymentDetails.merchantData.
 	// Payment hosts may use invoice numbers or any other data they require to match Payments to PaymentRequests.
-	// Note that malicious clients may modify the merchantData, so should be authenticated
-	// in some way (for example, signed with a payment host-only key).
+	// Malicious clients may modify the merchantData, so payment hosts should authenticate it
+	// with the AuthTag issued by service.NewMerchantDataTagger and checked by service.NewMerchantDataVerifier.
 	// Maximum length is 10000 characters.
-	MerchantData Merchant `json:"merchantData"`
-	// RefundTo is a paymail to send a refund to should a refund be necessary.
+	MerchantData Beneficiary `json:"merchantData"`
+	// Originator optionally identifies the payer to the merchant.
+	Originator *Originator `json:"originator,omitempty"`
+	// RefundTo is a paymail to send a refund to should a refund be necessary, it
+	// is resolved to a payment destination using a PaymailResolver.
 	// Maximum length is 100 characters
 	RefundTo *string `json:"refundTo"  swaggertype:"primitive,string" example:"me@paymail.com"`
 	// Memo is a plain-text note from the customer to the payment host.
@@ -34,20 +40,28 @@ type Payment struct {
 	// This is especially useful if they are receiving change and means when they use it
 	// as an input, they can provide the merkle proof.
 	ProofCallbacks map[string]ProofCallback `json:"proofCallbacks"`
+	// ModeID is the id of the DPP 1.0 payment mode chosen from the PaymentRequest modes, ie
+	// PaymentModeHybrid. When empty the payment is made to the PaymentRequest destinations.
+	ModeID string `json:"modeId,omitempty" example:"ef63d9775da5"`
+	// Mode is the mode specific payment, ie a HybridPayment, it is required when ModeID is set.
+	Mode json.RawMessage `json:"mode,omitempty" swaggertype:"object"`
+	// Encrypted contains the Memo, RefundTo and Originator ECIES encrypted to the PaymentRequest
+	// PublicKey and base64 encoded, those fields must be empty when it is supplied.
+	Encrypted string `json:"encrypted,omitempty"`
 }
 
 // Validate will ensure the users request is correct.
 func (p Payment) Validate() error {
 	v := validator.New().
 		Validate("ancestry/rawTx", func() error {
-			if p.RawTx == nil {
+			if p.RawTx == nil && p.ModeID == "" {
 				return errors.New("either ancestry or a rawTX are required")
 			}
 			return nil
 		}).
-		Validate("merchantData.extendedData", validator.NotEmpty(p.MerchantData.ExtendedData))
-	if p.MerchantData.ExtendedData != nil {
-		v = v.Validate("merchantData.paymentReference", validator.NotEmpty(p.MerchantData.ExtendedData["paymentReference"]))
+		Validate("merchantData.paymentReference", validator.NotEmpty(p.MerchantData.Reference()))
+	if p.Originator != nil {
+		v = v.Validate("originator", p.Originator.Validate)
 	}
 
 	if p.RawTx != nil {
@@ -58,8 +72,31 @@ func (p Payment) Validate() error {
 			return nil
 		})
 	}
-	if p.RefundTo != nil {
-		v = v.Validate("refundTo", validator.StrLength(*p.RefundTo, 0, 100))
+	if p.ModeID != "" {
+		v = v.Validate("mode", validator.NotEmpty(p.Mode)).
+			Validate("rawTx", func() error {
+				if p.RawTx != nil {
+					return errors.New("rawTx cannot be supplied with a modeId, send the transactions in the mode")
+				}
+				return nil
+			})
+	}
+	if p.Encrypted != "" {
+		v = v.Validate("encrypted", func() error {
+			if p.Memo != "" || p.RefundTo != nil || p.Originator != nil {
+				return errors.New("memo, refundTo and originator must be encrypted when encrypted is supplied")
+			}
+			return nil
+		})
+	}
+	// an empty refundTo has always been accepted and means no refund paymail was supplied.
+	if p.RefundTo != nil && *p.RefundTo != "" {
+		v = v.Validate("refundTo", func() error {
+			if err := validator.StrLength(*p.RefundTo, 0, 100)(); err != nil {
+				return err
+			}
+			return ValidatePaymail(*p.RefundTo)()
+		})
 	}
 	return v.Err()
 }
@@ -82,6 +119,9 @@ type PaymentACK struct {
 	// it is recommended only to use “1” and to fill the memo with a textual explanation about why
 	// the transaction was not accepted until further numbers are defined and standardised.
 	Error int `json:"error,omitempty"`
+	// Receipt is this PaymentACK signed by the merchant key, it is set when ACK signing is
+	// enabled and can be checked with VerifyPaymentACK and kept as proof the payment was accepted.
+	Receipt *envelope.JSONEnvelope `json:"receipt,omitempty"`
 }
 
 // PeerChannelData holds peer channel information for subscribing to and reading from a peer channel.
@@ -95,8 +135,19 @@ type PeerChannelData struct {
 // PaymentCreateArgs identifies the paymentID used for the payment.
 type PaymentCreateArgs struct {
 	PaymentID string `param:"paymentID"`
+	// ProofToken is set by the server, not the payer, to the bearer token the
+	// PaymentWriter should give mAPI as the callbackToken when broadcasting.
+	ProofToken string `json:"-"`
+	// Outbox is set by the server when webhooks are enabled. The PaymentWriter must call it with
+	// the ACK of an accepted payment and store the messages returned in the same transaction as
+	// the payment, so they are queued only if the payment is stored. Writers that cannot store
+	// outbox messages should return an error when it is set.
+	Outbox PaymentOutboxFunc `json:"-"`
 }
 
+// PaymentOutboxFunc returns the webhooks describing an accepted payment.
+type PaymentOutboxFunc func(ack PaymentACK) ([]OutboxMessage, error)
+
 // Validate will ensure that the PaymentCreateArgs are supplied and correct.
 func (p PaymentCreateArgs) Validate() error {
 	return validator.New().
@@ -109,7 +160,123 @@ type PaymentService interface {
 	PaymentCreate(ctx context.Context, args PaymentCreateArgs, req Payment) (*PaymentACK, error)
 }
 
+// UTXOReader returns the output spent by a transaction input, it is used to read the
+// values of the inputs a payment spends so the fees it pays can be checked.
+type UTXOReader interface {
+	// UTXO returns the output or ErrUTXONotFound.
+	UTXO(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error)
+}
+
+// UTXOWriter stores outputs to be read by a UTXOReader.
+type UTXOWriter interface {
+	UTXOCreate(ctx context.Context, utxos ...*bt.UTXO) error
+}
+
 // PaymentWriter will write a payment to a data store.
 type PaymentWriter interface {
 	PaymentCreate(ctx context.Context, args PaymentCreateArgs, req Payment) (*PaymentACK, error)
 }
+
+// PaymentRecord is a Payment that has been accepted and stored against an invoice.
+type PaymentRecord struct {
+	// PaymentID is the id of the invoice the payment was made against.
+	PaymentID string `json:"paymentId"`
+	// TxID is the id of the payment transaction.
+	TxID string `json:"txid"`
+	// State is the current state of the invoice the payment was made against.
+	State InvoiceState `json:"state"`
+	// PaymentReference is the reference echoed back by the payer in the MerchantData.
+	PaymentReference string `json:"paymentReference"`
+	// Merchant is the name of the merchant that requested the payment.
+	Merchant string `json:"merchant"`
+	// Amount is the number of satoshis paid to the invoice destinations.
+	Amount uint64 `json:"amount"`
+	// Payment is the payment as it was received.
+	Payment Payment `json:"payment"`
+	// CreatedAt is the time the payment was stored.
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// PaymentsArgs are used to filter and page through stored payments.
+// All filters are optional and are combined, empty values are ignored.
+type PaymentsArgs struct {
+	// PaymentID returns payments made against this invoice.
+	PaymentID string `query:"paymentId"`
+	// State returns payments whose invoice is in this state.
+	State InvoiceState `query:"state"`
+	// From returns payments created at or after this time.
+	From time.Time `query:"from"`
+	// To returns payments created before this time.
+	To time.Time `query:"to"`
+	// TxID returns the payment with this transaction id.
+	TxID string `query:"txid"`
+	// PaymentReference returns payments matching this reference.
+	PaymentReference string `query:"paymentReference"`
+	// Merchant returns payments requested by this merchant.
+	Merchant string `query:"merchant"`
+	// MinAmount returns payments of at least this many satoshis.
+	MinAmount uint64 `query:"minAmount"`
+	// MaxAmount returns payments of at most this many satoshis.
+	MaxAmount uint64 `query:"maxAmount"`
+	// Cursor is the opaque NextCursor returned from a previous page.
+	Cursor string `query:"cursor"`
+	// Limit is the maximum number of payments to return, defaults to PaymentsDefaultLimit.
+	Limit int `query:"limit"`
+}
+
+// Payment paging limits.
+const (
+	PaymentsDefaultLimit = 50
+	PaymentsMaxLimit     = 500
+)
+
+// Validate will ensure that the PaymentsArgs are correct.
+func (p PaymentsArgs) Validate() error {
+	v := validator.New().
+		Validate("limit", validator.BetweenInt(p.Limit, 0, PaymentsMaxLimit))
+	if p.State != "" {
+		v = v.Validate("state", validator.AnyString(string(p.State),
+			string(InvoiceStateCreated), string(InvoiceStatePending), string(InvoiceStatePaid),
+			string(InvoiceStateBroadcast), string(InvoiceStateConfirmed), string(InvoiceStateExpired),
+			string(InvoiceStateCancelled), string(InvoiceStateRefunded), string(InvoiceStatePartiallyRefunded)))
+	}
+	if !p.From.IsZero() && !p.To.IsZero() {
+		v = v.Validate("to", validator.DateAfter(p.To, p.From))
+	}
+	if p.MaxAmount > 0 {
+		v = v.Validate("maxAmount", validator.MinUInt64(p.MaxAmount, p.MinAmount))
+	}
+	return v.Err()
+}
+
+// Matches returns true if the record satisfies all of the filters in the args, the
+// cursor and limit are ignored.
+func (p PaymentsArgs) Matches(r PaymentRecord) bool {
+	switch {
+	case p.PaymentID != "" && r.PaymentID != p.PaymentID,
+		p.State != "" && r.State != p.State,
+		!p.From.IsZero() && r.CreatedAt.Before(p.From),
+		!p.To.IsZero() && !r.CreatedAt.Before(p.To),
+		p.TxID != "" && r.TxID != p.TxID,
+		p.PaymentReference != "" && r.PaymentReference != p.PaymentReference,
+		p.Merchant != "" && r.Merchant != p.Merchant,
+		r.Amount < p.MinAmount,
+		p.MaxAmount > 0 && r.Amount > p.MaxAmount:
+		return false
+	}
+	return true
+}
+
+// PaymentsPage is a single page of stored payments, newest first.
+type PaymentsPage struct {
+	Payments []PaymentRecord `json:"payments"`
+	// NextCursor can be supplied in PaymentsArgs to fetch the next page, it
+	// is empty when there are no more results.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PaymentReader will return stored payments.
+type PaymentReader interface {
+	// Payments returns a page of payments matching the args.
+	Payments(ctx context.Context, args PaymentsArgs) (*PaymentsPage, error)
+}
@@ -0,0 +1,106 @@
+package dpp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PaymentConflictReason describes why a payment conflicts with one already received.
+type PaymentConflictReason string
+
+// Supported payment conflict reasons.
+const (
+	// PaymentConflictTxReused means the transaction has already been used to pay another invoice.
+	PaymentConflictTxReused PaymentConflictReason = "tx_reused"
+	// PaymentConflictOutpointSpent means the transaction spends an outpoint already
+	// spent by a different payment transaction.
+	PaymentConflictOutpointSpent PaymentConflictReason = "outpoint_spent"
+	// PaymentConflictInProgress means the same payment is currently being processed.
+	PaymentConflictInProgress PaymentConflictReason = "in_progress"
+)
+
+// PaymentConflictError is returned when a payment is rejected because it
+// conflicts with a payment that has already been received.
+type PaymentConflictError struct {
+	Reason PaymentConflictReason
+	// PaymentID is the invoice the rejected payment was made against.
+	PaymentID string
+	// TxID is the rejected payment transaction.
+	TxID string
+	// ConflictPaymentID is the invoice of the existing payment.
+	ConflictPaymentID string
+	// ConflictTxID is the existing payment transaction.
+	ConflictTxID string
+	// Outpoint is the outpoint spent by both transactions, set for PaymentConflictOutpointSpent.
+	Outpoint string
+}
+
+// Error satisfies the error interface.
+func (e PaymentConflictError) Error() string {
+	switch e.Reason {
+	case PaymentConflictTxReused:
+		return fmt.Sprintf("tx %s has already been used to pay invoice %s", e.TxID, e.ConflictPaymentID)
+	case PaymentConflictOutpointSpent:
+		return fmt.Sprintf("tx %s spends outpoint %s already spent by tx %s", e.TxID, e.Outpoint, e.ConflictTxID)
+	case PaymentConflictInProgress:
+		return fmt.Sprintf("payment of tx %s to invoice %s is already being processed", e.TxID, e.PaymentID)
+	}
+	return fmt.Sprintf("payment of tx %s to invoice %s conflicts with an existing payment", e.TxID, e.PaymentID)
+}
+
+// Conflict indicates the payment conflicts with one already received.
+func (e PaymentConflictError) Conflict() bool {
+	return true
+}
+
+// PaymentIndexEntry records a payment transaction and the outpoints it spends.
+type PaymentIndexEntry struct {
+	PaymentID string
+	TxID      string
+	// Outpoints are the outpoints spent by the transaction in the format txid:vout.
+	Outpoints []string
+	// ACK is the PaymentACK returned when the payment was accepted, it
+	// is nil while the payment is being processed.
+	ACK *PaymentACK
+	// CreatedAt is the time the entry was created, stores must persist it so
+	// abandoned entries can be expired.
+	CreatedAt time.Time
+}
+
+// PaymentIndexInProgressTimeout is how long an entry can be in progress before it is
+// considered abandoned, ie the ACK failed to be stored, and the payment can be retried.
+const PaymentIndexInProgressTimeout = 10 * time.Minute
+
+// Abandoned returns true if the entry has been in progress for longer than PaymentIndexInProgressTimeout.
+func (e PaymentIndexEntry) Abandoned(now time.Time) bool {
+	return e.ACK == nil && now.Sub(e.CreatedAt) > PaymentIndexInProgressTimeout
+}
+
+// ErrPaymentIndexNotFound is returned when a payment index entry does not exist.
+var ErrPaymentIndexNotFound = errors.New("payment index entry not found")
+
+// PaymentIndexReader reads indexed payment transactions.
+type PaymentIndexReader interface {
+	// PaymentIndex returns the entry for a txid or ErrPaymentIndexNotFound.
+	PaymentIndex(ctx context.Context, txID string) (*PaymentIndexEntry, error)
+}
+
+// PaymentIndexWriter writes indexed payment transactions.
+type PaymentIndexWriter interface {
+	// PaymentIndexCreate will reserve the txid and outpoints of the entry. It must be atomic
+	// and return a PaymentConflictError if the txid or any outpoint is already indexed.
+	PaymentIndexCreate(ctx context.Context, req PaymentIndexEntry) error
+	// PaymentIndexUpdate will store the ACK returned for an accepted payment.
+	PaymentIndexUpdate(ctx context.Context, txID string, ack PaymentACK) error
+	// PaymentIndexDelete will release the txid and outpoints of a rejected payment.
+	PaymentIndexDelete(ctx context.Context, txID string) error
+}
+
+// PaymentIndexReaderWriter combines the reader and writer interfaces.
+type PaymentIndexReaderWriter interface {
+	PaymentIndexReader
+	PaymentIndexWriter
+}
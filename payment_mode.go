@@ -0,0 +1,191 @@
+package dpp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PaymentModeHybrid is the id of the DPP 1.0 HybridPaymentMode.
+// See https://tsc.bitcoinassociation.net/standards/direct_payment_protocol/
+const PaymentModeHybrid = "ef63d9775da5"
+
+// HybridDefaultOptionID is the option id used when the PaymentRequest destinations are offered as a HybridPaymentMode.
+const HybridDefaultOptionID = "choiceID0"
+
+// PaymentModeHandler validates payments made using a payment mode.
+type PaymentModeHandler interface {
+	// ModeID returns the id of the mode handled, ie PaymentModeHybrid.
+	ModeID() string
+	// ValidatePayment checks the chosen mode payload from a Payment satisfies the
+	// options offered in the PaymentRequest and returns the payment transactions.
+	ValidatePayment(offered, chosen json.RawMessage) ([]*bt.Tx, error)
+}
+
+// PaymentModes is a registry of PaymentModeHandlers keyed by mode id.
+type PaymentModes map[string]PaymentModeHandler
+
+// NewPaymentModes will setup and return a registry containing the handlers.
+func NewPaymentModes(hh ...PaymentModeHandler) PaymentModes {
+	m := make(PaymentModes, len(hh))
+	for _, h := range hh {
+		m.Register(h)
+	}
+	return m
+}
+
+// Register adds a handler, replacing any existing handler for the same mode.
+func (m PaymentModes) Register(h PaymentModeHandler) {
+	m[h.ModeID()] = h
+}
+
+// Validate will check the payment satisfies the PaymentRequest and return its transactions.
+//
+// Payments without a ModeID are validated against the request Destinations, otherwise the
+// mode must be offered by the request and registered, its handler validates the payment.
+func (m PaymentModes) Validate(pr PaymentRequest, p Payment) ([]*bt.Tx, error) {
+	if p.ModeID == "" {
+		if p.RawTx == nil {
+			return nil, validator.ErrValidation{"rawTx": []string{"rawTx is required when no modeId is supplied"}}
+		}
+		tx, err := bt.NewTxFromString(*p.RawTx)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid rawTx supplied")
+		}
+		if err := pr.ValidateTx(tx); err != nil {
+			return nil, err
+		}
+		return []*bt.Tx{tx}, nil
+	}
+	offered, ok := pr.Modes[p.ModeID]
+	if !ok {
+		return nil, validator.ErrValidation{"modeId": []string{fmt.Sprintf("mode %s is not offered by the payment request", p.ModeID)}}
+	}
+	h, ok := m[p.ModeID]
+	if !ok {
+		return nil, validator.ErrValidation{"modeId": []string{fmt.Sprintf("mode %s is not supported", p.ModeID)}}
+	}
+	return h.ValidatePayment(offered, p.Mode)
+}
+
+// HybridPaymentMode contains the options offered by a merchant keyed by option id, the
+// payer chooses one option and funds every transaction in it.
+type HybridPaymentMode map[string]HybridPaymentOption
+
+// NewHybridPaymentMode returns a HybridPaymentMode with a single option, HybridDefaultOptionID,
+// of one transaction paying the destination outputs and data outputs, which have no amount and
+// must be paid with a zero value.
+func NewHybridPaymentMode(dests PaymentDestinations, fees *bt.FeeQuote, spvRequired bool) (HybridPaymentMode, error) {
+	native := make([]Output, 0, len(dests.Outputs)+len(dests.Data))
+	native = append(native, dests.Outputs...)
+	for i, d := range dests.Data {
+		script, err := d.LockingScript()
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid data output %d", i)
+		}
+		native = append(native, Output{LockingScript: script, Description: d.Description})
+	}
+	return HybridPaymentMode{
+		HybridDefaultOptionID: {
+			Transactions: []HybridTransaction{{
+				Outputs: HybridOutputs{Native: native},
+				Policies: &HybridPolicies{
+					FeeRate:     fees,
+					SPVRequired: spvRequired,
+				},
+			}},
+		},
+	}, nil
+}
+
+// HybridPaymentOption is a funding option made up of one or more transactions.
+type HybridPaymentOption struct {
+	Transactions []HybridTransaction `json:"transactions"`
+}
+
+// HybridTransaction describes a transaction the payer must create.
+type HybridTransaction struct {
+	Outputs  HybridOutputs   `json:"outputs"`
+	Policies *HybridPolicies `json:"policies,omitempty"`
+}
+
+// HybridOutputs are the outputs a transaction must contain.
+type HybridOutputs struct {
+	// Native are outputs paying satoshis to a locking script.
+	Native []Output `json:"native"`
+}
+
+// HybridPolicies are the rules a transaction must follow.
+type HybridPolicies struct {
+	// FeeRate is the fee the transaction should pay.
+	FeeRate *bt.FeeQuote `json:"fees,omitempty"`
+	// SPVRequired if true the payer must send the ancestry of the transaction.
+	SPVRequired bool `json:"SPVRequired"`
+	// LockTime is the lock time the transaction must use.
+	LockTime uint32 `json:"lockTime,omitempty"`
+}
+
+// HybridPayment is the mode payload sent in a Payment using the HybridPaymentMode.
+type HybridPayment struct {
+	// OptionID is the id of the option chosen.
+	OptionID string `json:"optionId"`
+	// Transactions are the hex encoded transactions funding the option, in option order.
+	Transactions []string `json:"transactions"`
+}
+
+// HybridPaymentModeHandler validates payments made using the HybridPaymentMode.
+type HybridPaymentModeHandler struct{}
+
+// ModeID returns PaymentModeHybrid.
+func (HybridPaymentModeHandler) ModeID() string {
+	return PaymentModeHybrid
+}
+
+// ValidatePayment checks the chosen option was offered and each transaction pays the outputs
+// of the matching option transaction and uses its lock time. Outputs with no amount, ie data
+// outputs, must have a zero value. Fees are not checked as a raw transaction does not contain
+// the value of its inputs.
+func (HybridPaymentModeHandler) ValidatePayment(offered, chosen json.RawMessage) ([]*bt.Tx, error) {
+	var mode HybridPaymentMode
+	if err := json.Unmarshal(offered, &mode); err != nil {
+		return nil, errors.Wrap(err, "invalid hybrid payment mode offered")
+	}
+	var payment HybridPayment
+	if len(chosen) == 0 {
+		return nil, validator.ErrValidation{"mode": []string{"mode is required when modeId is supplied"}}
+	}
+	if err := json.Unmarshal(chosen, &payment); err != nil {
+		return nil, validator.ErrValidation{"mode": []string{"invalid hybrid payment: " + err.Error()}}
+	}
+	option, ok := mode[payment.OptionID]
+	if !ok {
+		return nil, validator.ErrValidation{"mode.optionId": []string{fmt.Sprintf("option %s is not offered", payment.OptionID)}}
+	}
+	if len(payment.Transactions) != len(option.Transactions) {
+		return nil, validator.ErrValidation{"mode.transactions": []string{
+			fmt.Sprintf("option %s requires %d transactions", payment.OptionID, len(option.Transactions)),
+		}}
+	}
+	txs := make([]*bt.Tx, 0, len(payment.Transactions))
+	for i, raw := range payment.Transactions {
+		tx, err := bt.NewTxFromString(raw)
+		if err != nil {
+			return nil, validator.ErrValidation{fmt.Sprintf("mode.transactions[%d]", i): []string{"invalid transaction"}}
+		}
+		expected := option.Transactions[i]
+		pr := PaymentRequest{Destinations: PaymentDestinations{Outputs: expected.Outputs.Native}}
+		if err := pr.ValidateTx(tx); err != nil {
+			return nil, errors.Wrapf(err, "mode.transactions[%d]", i)
+		}
+		if expected.Policies != nil && tx.LockTime != expected.Policies.LockTime {
+			return nil, validator.ErrValidation{fmt.Sprintf("mode.transactions[%d]", i): []string{
+				fmt.Sprintf("lockTime must be %d", expected.Policies.LockTime),
+			}}
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
@@ -0,0 +1,128 @@
+package dpp_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestPaymentModes_Validate(t *testing.T) {
+	script, err := bscript.NewFromHexString(p2pkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dests := dpp.PaymentDestinations{
+		Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}},
+		Data:    []dpp.DataOutput{{Pushes: []string{"6f72646572"}}},
+	}
+	hybrid, err := dpp.NewHybridPaymentMode(dests, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hybrid["choiceID1"] = dpp.HybridPaymentOption{Transactions: []dpp.HybridTransaction{
+		{Outputs: dpp.HybridOutputs{Native: []dpp.Output{{Amount: 600, LockingScript: script}}}},
+		{
+			Outputs:  dpp.HybridOutputs{Native: []dpp.Output{{Amount: 400, LockingScript: script}}},
+			Policies: &dpp.HybridPolicies{LockTime: 10},
+		},
+	}}
+	hybridJSON, err := json.Marshal(hybrid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := dpp.PaymentRequest{
+		Destinations: dests,
+		Modes:        map[string]json.RawMessage{dpp.PaymentModeHybrid: hybridJSON},
+	}
+	dataScript, err := dests.Data[0].LockingScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newTx := func(sats uint64, lockTime uint32, data bool) string {
+		tx := bt.NewTx()
+		tx.LockTime = lockTime
+		tx.AddOutput(&bt.Output{Satoshis: sats, LockingScript: script})
+		if data {
+			tx.AddOutput(&bt.Output{LockingScript: dataScript})
+		}
+		return tx.String()
+	}
+	valuedData := func() string {
+		tx := bt.NewTx()
+		tx.AddOutput(&bt.Output{Satoshis: 1000, LockingScript: script})
+		tx.AddOutput(&bt.Output{Satoshis: 1, LockingScript: dataScript})
+		return tx.String()
+	}()
+	mode := func(optionID string, txs ...string) json.RawMessage {
+		bb, err := json.Marshal(dpp.HybridPayment{OptionID: optionID, Transactions: txs})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return bb
+	}
+	legacy := newTx(1000, 0, true)
+	tests := map[string]struct {
+		payment dpp.Payment
+		expTxs  int
+		err     string
+	}{
+		"payment without a mode uses destinations": {
+			payment: dpp.Payment{RawTx: &legacy},
+			expTxs:  1,
+		},
+		"hybrid default option": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode(dpp.HybridDefaultOptionID, newTx(1000, 0, true))},
+			expTxs:  1,
+		},
+		"hybrid default option missing data output": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode(dpp.HybridDefaultOptionID, newTx(1000, 0, false))},
+			err:     "mode.transactions[0]: [destinations.outputs[1]: tx does not contain zero value output]",
+		},
+		"hybrid default option with data output carrying value": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode(dpp.HybridDefaultOptionID, valuedData)},
+			err:     "mode.transactions[0]: [destinations.outputs[1]: tx does not contain zero value output]",
+		},
+		"hybrid option with several transactions": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode("choiceID1", newTx(600, 0, false), newTx(400, 10, false))},
+			expTxs:  2,
+		},
+		"hybrid option with wrong lock time": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode("choiceID1", newTx(600, 0, false), newTx(400, 0, false))},
+			err:     "[mode.transactions[1]: lockTime must be 10]",
+		},
+		"hybrid option with missing transaction": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode("choiceID1", newTx(600, 0, false))},
+			err:     "[mode.transactions: option choiceID1 requires 2 transactions]",
+		},
+		"unknown option": {
+			payment: dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode("choiceID9", newTx(1000, 0, true))},
+			err:     "[mode.optionId: option choiceID9 is not offered]",
+		},
+		"mode not offered": {
+			payment: dpp.Payment{ModeID: "abc123", Mode: mode("choiceID0")},
+			err:     "[modeId: mode abc123 is not offered by the payment request]",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			txs, err := dpp.NewPaymentModes(dpp.HybridPaymentModeHandler{}).Validate(pr, test.payment)
+			if test.err != "" {
+				is.True(err != nil)
+				is.Equal(err.Error(), test.err)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(len(txs), test.expTxs)
+		})
+	}
+
+	// a mode offered by the request but not registered is rejected.
+	_, err = dpp.NewPaymentModes().Validate(pr, dpp.Payment{ModeID: dpp.PaymentModeHybrid, Mode: mode(dpp.HybridDefaultOptionID)})
+	is.New(t).Equal(err.Error(), "[modeId: mode ef63d9775da5 is not supported]")
+}
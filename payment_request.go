@@ -2,9 +2,14 @@ package dpp
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
 )
 
 // PaymentRequest message used in BIP270.
@@ -33,14 +38,131 @@ type PaymentRequest struct {
 	// Memo Optional note that should be displayed to the customer, explaining what this PaymentRequest is for.
 	// Maximum length is 50 characters.
 	Memo string `json:"memo" example:"invoice number 123456"`
-	// MerchantData contains arbitrary data that may be used by the payment host to identify the PaymentRequest.
-	// May be omitted if the payment host does not need to associate Payments with PaymentRequest
-	// or if they associate each PaymentRequest with a separate payment address.
-	// Maximum length is 10000 characters.
-	MerchantData *Merchant `json:"merchantData,omitempty"`
+	// Beneficiary contains the merchant details and the PaymentReference used by the payment host
+	// to identify the PaymentRequest, the payer echoes it back as the Payment MerchantData.
+	// It is also written as merchantData for wallets reading the old shape.
+	Beneficiary *Beneficiary `json:"beneficiary,omitempty"`
+	// MerchantData is the previous name of Beneficiary, it is only read when Beneficiary is nil.
+	//
+	// Deprecated: use Beneficiary.
+	MerchantData *Merchant `json:"-"`
 	// FeeRate defines the amount of fees a users wallet should add to the payment
 	// when submitting their final payments.
 	FeeRate *bt.FeeQuote `json:"fees"`
+	// Modes are the DPP 1.0 payment modes offered keyed by mode id, ie PaymentModeHybrid
+	// with a HybridPaymentMode. The payer chooses one mode and one of its options.
+	Modes map[string]json.RawMessage `json:"modes,omitempty" swaggertype:"object"`
+	// PublicKey is the hex encoded merchant public key, when supplied wallets should
+	// encrypt the Payment memo, refundTo and originator to it.
+	PublicKey string `json:"publicKey,omitempty" example:"0294d0ee9d4da2b6a1d8e12bfd4f4b2cc7c69fe4ee2cbdc02d3e3f0e4a5a0d3a5c"`
+}
+
+// Payee returns the Beneficiary, or the deprecated MerchantData when no Beneficiary is set.
+func (p PaymentRequest) Payee() *Beneficiary {
+	if p.Beneficiary != nil {
+		return p.Beneficiary
+	}
+	return p.MerchantData
+}
+
+// UnmarshalJSON will decode the PaymentRequest, setting the locking script of
+// any outputs supplied as BIP276 strings and rejecting unknown or mismatched ones.
+// A merchantData object is read as the Beneficiary when no beneficiary is supplied.
+func (p *PaymentRequest) UnmarshalJSON(bb []byte) error {
+	type alias PaymentRequest
+	var pr struct {
+		alias
+		MerchantData *Beneficiary `json:"merchantData"`
+	}
+	if err := json.Unmarshal(bb, &pr); err != nil {
+		return err
+	}
+	if err := decodeOutputs(pr.Network, pr.Destinations.Outputs); err != nil {
+		return err
+	}
+	if pr.Beneficiary == nil {
+		pr.Beneficiary = pr.MerchantData
+	}
+	*p = PaymentRequest(pr.alias)
+	return nil
+}
+
+// MarshalJSON will encode the PaymentRequest, writing the Payee as both
+// beneficiary and merchantData for wallets reading the old shape.
+func (p PaymentRequest) MarshalJSON() ([]byte, error) {
+	type alias PaymentRequest
+	a := alias(p)
+	a.Beneficiary = p.Payee()
+	return json.Marshal(struct {
+		alias
+		MerchantData *Beneficiary `json:"merchantData,omitempty"`
+	}{
+		alias:        a,
+		MerchantData: a.Beneficiary,
+	})
+}
+
+// ValidateTx will check the tx pays every destination output and contains every
+// data output byte for byte with a zero value. Destination outputs with no amount
+// must also have a zero value.
+func (p PaymentRequest) ValidateTx(tx *bt.Tx) error {
+	used := make([]bool, len(tx.Outputs))
+	// match finds an unused tx output with the script and a value accepted by ok.
+	match := func(script *bscript.Script, ok func(sats uint64) bool) bool {
+		for i, o := range tx.Outputs {
+			if !used[i] && o.LockingScript.Equals(script) && ok(o.Satoshis) {
+				used[i] = true
+				return true
+			}
+		}
+		return false
+	}
+	v := validator.New()
+	for i, o := range p.Destinations.Outputs {
+		v = v.Validate(fmt.Sprintf("destinations.outputs[%d]", i), func() error {
+			if o.Amount == 0 {
+				if o.LockingScript == nil || !match(o.LockingScript, func(sats uint64) bool { return sats == 0 }) {
+					return errors.New("tx does not contain zero value output")
+				}
+				return nil
+			}
+			if o.LockingScript == nil || !match(o.LockingScript, func(sats uint64) bool { return sats >= o.Amount }) {
+				return fmt.Errorf("tx does not pay %d satoshis to output", o.Amount)
+			}
+			return nil
+		})
+	}
+	for i, d := range p.Destinations.Data {
+		v = v.Validate(fmt.Sprintf("destinations.data[%d]", i), func() error {
+			script, err := d.LockingScript()
+			if err != nil {
+				return err
+			}
+			if !match(script, func(sats uint64) bool { return sats == 0 }) {
+				return errors.New("tx does not contain zero value data output")
+			}
+			return nil
+		})
+	}
+	return v.Err()
+}
+
+// ValidateFees will check the tx pays enough fees at the FeeRate, with data outputs
+// charged at the FeeTypeData rate. The PreviousTxSatoshis of every input must be set,
+// ie from the ancestry, as a raw tx does not contain the value of its inputs.
+func (p PaymentRequest) ValidateFees(tx *bt.Tx) error {
+	fq := p.FeeRate
+	if fq == nil {
+		fq = bt.NewFeeQuote()
+	}
+	ok, err := tx.IsFeePaidEnough(fq)
+	if err != nil {
+		return errors.Wrap(err, "failed to calculate fees")
+	}
+	if !ok {
+		return validator.ErrValidation{"fees": []string{"tx does not pay enough fees"}}
+	}
+	return nil
 }
 
 // PaymentRequestArgs are request arguments that can be passed to the service.
@@ -49,6 +171,93 @@ type PaymentRequestArgs struct {
 	PaymentID string `param:"paymentID"`
 }
 
+// Validate will ensure that the PaymentRequestArgs are supplied and correct.
+func (p PaymentRequestArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Err()
+}
+
+// PaymentRequestCreate contains the information required to create a new PaymentRequest.
+//
+// Either Amounts or Outputs should be supplied, when Amounts are supplied a destination
+// is generated for each amount, when Outputs are supplied they are used as is.
+type PaymentRequestCreate struct {
+	// Amounts are the satoshi values to request, one destination is created for each.
+	Amounts []uint64 `json:"amounts" example:"1000,2000"`
+	// Outputs can be supplied to request payment to explicit locking scripts.
+	Outputs []Output `json:"outputs"`
+	// Memo is an optional note that will be displayed to the customer.
+	// Maximum length is 50 characters.
+	Memo string `json:"memo" example:"invoice number 123456"`
+	// ExpirationTimestamp is the time after which the PaymentRequest cannot be paid.
+	// Optional.
+	ExpirationTimestamp time.Time `json:"expirationTimestamp" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	// Beneficiary contains the merchant details to display to the customer, the
+	// PaymentReference is generated.
+	Beneficiary Beneficiary `json:"beneficiary"`
+	// MerchantData is the previous name of Beneficiary, it is only read when Beneficiary is empty.
+	//
+	// Deprecated: use Beneficiary.
+	MerchantData Merchant `json:"-"`
+	// AncestryRequired if true will require the payer to submit an ancestry rather than a rawTx.
+	AncestryRequired bool `json:"ancestryRequired" example:"true"`
+	// Data are OP_RETURN outputs the payment must contain, such as an order hash.
+	Data []DataOutput `json:"data"`
+}
+
+// Payee returns the Beneficiary, or the deprecated MerchantData when the Beneficiary is empty.
+func (p PaymentRequestCreate) Payee() Beneficiary {
+	if p.Beneficiary.isZero() {
+		return p.MerchantData
+	}
+	return p.Beneficiary
+}
+
+// Validate will ensure the PaymentRequestCreate is valid.
+func (p PaymentRequestCreate) Validate() error {
+	v := validator.New().
+		Validate("amounts/outputs", func() error {
+			if len(p.Amounts) == 0 && len(p.Outputs) == 0 {
+				return errors.New("either amounts or outputs are required")
+			}
+			if len(p.Amounts) > 0 && len(p.Outputs) > 0 {
+				return errors.New("only one of amounts or outputs should be supplied")
+			}
+			return nil
+		}).
+		Validate("memo", validator.StrLength(p.Memo, 0, 50)).
+		Validate("beneficiary", p.Payee().validateDetails).
+		Validate("expirationTimestamp", func() error {
+			if p.ExpirationTimestamp.IsZero() {
+				return nil
+			}
+			return validator.DateAfter(p.ExpirationTimestamp, time.Now().UTC())()
+		})
+	for i, a := range p.Amounts {
+		v = v.Validate(fmt.Sprintf("amounts[%d]", i), validator.PositiveUInt64(a))
+	}
+	for i, o := range p.Outputs {
+		v = v.Validate(fmt.Sprintf("outputs[%d].amount", i), validator.PositiveUInt64(o.Amount)).
+			Validate(fmt.Sprintf("outputs[%d].script", i), func() error {
+				if o.BIP276 != "" {
+					_, err := ParseBIP276(o.BIP276)
+					return err
+				}
+				return validator.NotEmpty(o.LockingScript)()
+			}).
+			Validate(fmt.Sprintf("outputs[%d].description", i), validator.StrLength(o.Description, 0, 100))
+	}
+	for i, d := range p.Data {
+		v = v.Validate(fmt.Sprintf("data[%d].pushes", i), func() error {
+			_, err := d.LockingScript()
+			return err
+		}).
+			Validate(fmt.Sprintf("data[%d].description", i), validator.StrLength(d.Description, 0, 100))
+	}
+	return v.Err()
+}
+
 // PaymentRequestService can be implemented to enforce business rules
 // and process in order to fulfil a PaymentRequest.
 type PaymentRequestService interface {
@@ -59,3 +268,41 @@ type PaymentRequestService interface {
 type PaymentRequestReader interface {
 	PaymentRequest(ctx context.Context, args PaymentRequestArgs) (*PaymentRequest, error)
 }
+
+type internalReadKey struct{}
+
+// WithInternalRead returns a context marking PaymentRequest reads made by the payment host itself,
+// ie when validating a payment, rather than for a payer. They are not published or audited as served.
+func WithInternalRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalReadKey{}, true)
+}
+
+// InternalRead returns true if the context was marked with WithInternalRead.
+func InternalRead(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalReadKey{}).(bool)
+	return internal
+}
+
+// PaymentRequestCreator will create a new PaymentRequest, returning it with
+// a generated paymentID and PaymentURL.
+type PaymentRequestCreator interface {
+	PaymentRequestCreate(ctx context.Context, req PaymentRequestCreate) (*PaymentRequest, error)
+}
+
+// PaymentRequestReaderCreator combines the reader and creator interfaces, it is
+// implemented by payment hosts that create PaymentRequests as well as serve them.
+type PaymentRequestReaderCreator interface {
+	PaymentRequestReader
+	PaymentRequestCreator
+}
+
+// PaymentRequestWriter will persist a PaymentRequest to a data store.
+type PaymentRequestWriter interface {
+	PaymentRequestCreate(ctx context.Context, args PaymentRequestArgs, req PaymentRequest) (*PaymentRequest, error)
+}
+
+// PaymentRequestReaderWriter combines the reader and writer interfaces.
+type PaymentRequestReaderWriter interface {
+	PaymentRequestReader
+	PaymentRequestWriter
+}
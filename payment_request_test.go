@@ -0,0 +1,107 @@
+package dpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestPaymentRequest_ValidateTx(t *testing.T) {
+	script, err := bscript.NewFromHexString(p2pkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := dpp.DataOutput{Pushes: []string{"6f72646572", "9f86d081884c7d659a2feaa0c55ad015"}}
+	dataScript, err := data.LockingScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := (dpp.DataOutput{Pushes: []string{"6f72646572"}}).LockingScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr := dpp.PaymentRequest{Destinations: dpp.PaymentDestinations{
+		Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}},
+		Data:    []dpp.DataOutput{data},
+	}}
+	tests := map[string]struct {
+		outputs []*bt.Output
+		err     string
+	}{
+		"outputs and data present": {
+			outputs: []*bt.Output{
+				{Satoshis: 1000, LockingScript: script},
+				{LockingScript: dataScript},
+			},
+		},
+		"missing data output": {
+			outputs: []*bt.Output{{Satoshis: 1000, LockingScript: script}},
+			err:     "[destinations.data[0]: tx does not contain zero value data output]",
+		},
+		"different data output": {
+			outputs: []*bt.Output{
+				{Satoshis: 1000, LockingScript: script},
+				{LockingScript: other},
+			},
+			err: "[destinations.data[0]: tx does not contain zero value data output]",
+		},
+		"data output with value": {
+			outputs: []*bt.Output{
+				{Satoshis: 1000, LockingScript: script},
+				{Satoshis: 1, LockingScript: dataScript},
+			},
+			err: "[destinations.data[0]: tx does not contain zero value data output]",
+		},
+		"underpaid output": {
+			outputs: []*bt.Output{
+				{Satoshis: 999, LockingScript: script},
+				{LockingScript: dataScript},
+			},
+			err: "[destinations.outputs[0]: tx does not pay 1000 satoshis to output]",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			tx := bt.NewTx()
+			for _, o := range test.outputs {
+				tx.AddOutput(o)
+			}
+			err := pr.ValidateTx(tx)
+			if test.err == "" {
+				is.NoErr(err)
+				return
+			}
+			is.True(err != nil)
+			is.Equal(err.Error(), test.err)
+		})
+	}
+}
+
+func TestPaymentRequest_ValidateFees(t *testing.T) {
+	is := is.New(t)
+	script, err := bscript.NewFromHexString(p2pkh)
+	is.NoErr(err)
+	// a 1000 byte push costs 5 satoshis at the data rate but 500 at the standard rate.
+	dataScript, err := (dpp.DataOutput{Pushes: []string{strings.Repeat("ab", 1000)}}).LockingScript()
+	is.NoErr(err)
+	fq := bt.NewFeeQuote().
+		AddQuote(bt.FeeTypeStandard, &bt.Fee{MiningFee: bt.FeeUnit{Satoshis: 500, Bytes: 1000}}).
+		AddQuote(bt.FeeTypeData, &bt.Fee{MiningFee: bt.FeeUnit{Satoshis: 5, Bytes: 1000}})
+	pr := dpp.PaymentRequest{FeeRate: fq}
+
+	newTx := func(fee uint64) *bt.Tx {
+		tx := bt.NewTx()
+		is.NoErr(tx.From("b7b0650a7c3a1bd4716369783876348b59f5404784970192cec1996e86950576", 0, p2pkh, 1000+fee))
+		tx.AddOutput(&bt.Output{Satoshis: 1000, LockingScript: script})
+		tx.AddOutput(&bt.Output{LockingScript: dataScript})
+		return tx
+	}
+	is.NoErr(pr.ValidateFees(newTx(100)))
+	is.Equal(pr.ValidateFees(newTx(20)).Error(), "[fees: tx does not pay enough fees]")
+}
@@ -38,7 +38,7 @@ func TestPaymentCreate_Validate(t *testing.T) {
 				}(),
 				Memo: "test this please",
 			},
-			exp: "[merchantData.extendedData: value cannot be empty]",
+			exp: "[merchantData.paymentReference: value cannot be empty]",
 		}, "merchant data missing payment reference should error": {
 			req: Payment{
 				RawTx: func() *string {
@@ -72,6 +72,21 @@ func TestPaymentCreate_Validate(t *testing.T) {
 				Memo: "test this please",
 			},
 			exp: "[refundTo: value must be between 0 and 100 characters]",
+		}, "rawTx supplied with a modeId should error": {
+			req: Payment{
+				RawTx: func() *string {
+					s := "01000000000000000000"
+					return &s
+				}(),
+				ModeID: PaymentModeHybrid,
+				Mode:   []byte(`{"optionId":"choiceID0","transactions":[]}`),
+				MerchantData: Merchant{
+					ExtendedData: map[string]interface{}{
+						"paymentReference": "abc123",
+					},
+				},
+			},
+			exp: "[rawTx: rawTx cannot be supplied with a modeId, send the transactions in the mode]",
 		},
 	}
 	for name, test := range tests {
@@ -0,0 +1,170 @@
+package dpp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PeerChannelPath is the path peer channels are served from, a channel is found at
+// PeerChannelData.Host + PeerChannelData.Path + "/" + PeerChannelData.ChannelID.
+const PeerChannelPath = "/api/v1/channel"
+
+// URL returns the url of the channel, the host is assumed to be https if it has no scheme.
+func (p PeerChannelData) URL() string {
+	host := p.Host
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	path := p.Path
+	if path == "" {
+		path = PeerChannelPath
+	}
+	return strings.TrimSuffix(host, "/") + "/" + strings.Trim(path, "/") + "/" + p.ChannelID
+}
+
+// PeerChannelCreate contains the settings for a new peer channel.
+type PeerChannelCreate struct {
+	// PublicRead if true allows messages to be read without a token.
+	PublicRead bool `json:"public_read"`
+	// PublicWrite if true allows messages to be written without a token.
+	PublicWrite bool `json:"public_write"`
+}
+
+// PeerChannel is a channel that messages can be posted to and read from.
+type PeerChannel struct {
+	ID           string             `json:"id"`
+	Href         string             `json:"href"`
+	PublicRead   bool               `json:"public_read"`
+	PublicWrite  bool               `json:"public_write"`
+	Sequenced    bool               `json:"sequenced"`
+	AccessTokens []PeerChannelToken `json:"access_tokens"`
+	CreatedAt    time.Time          `json:"created_at" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// PeerChannelTokenCreate contains the permissions for a new channel token.
+type PeerChannelTokenCreate struct {
+	Description string `json:"description"`
+	CanRead     bool   `json:"can_read"`
+	CanWrite    bool   `json:"can_write"`
+}
+
+// Validate will ensure the token grants at least one permission.
+func (p PeerChannelTokenCreate) Validate() error {
+	return validator.New().
+		Validate("can_read/can_write", validator.Bool(p.CanRead || p.CanWrite, true)).
+		Validate("description", validator.StrLength(p.Description, 0, 1024)).
+		Err()
+}
+
+// PeerChannelToken is a bearer token granting access to a channel.
+type PeerChannelToken struct {
+	ID          string `json:"id"`
+	Token       string `json:"token"`
+	Description string `json:"description"`
+	CanRead     bool   `json:"can_read"`
+	CanWrite    bool   `json:"can_write"`
+}
+
+// PeerChannelMessage is a JSON message posted to a channel.
+type PeerChannelMessage struct {
+	// Sequence is the position of the message in the channel, starting at 1.
+	Sequence    uint64          `json:"sequence"`
+	Received    time.Time       `json:"received" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	ContentType string          `json:"content_type"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+}
+
+// PeerChannelMessagesArgs are used to read messages from a channel.
+type PeerChannelMessagesArgs struct {
+	// After returns messages with a sequence greater than this.
+	After uint64 `query:"after"`
+	// Wait if set will hold the request open for up to this long until a message is posted
+	// when there are no messages after the sequence, this allows a reader to long-poll.
+	Wait time.Duration `query:"wait"`
+}
+
+// PeerChannelCreator creates peer channels and tokens, it is used by the channel owner.
+type PeerChannelCreator interface {
+	// PeerChannelCreate creates a channel, it is returned with a read and write token.
+	PeerChannelCreate(ctx context.Context, req PeerChannelCreate) (*PeerChannel, error)
+	// PeerChannelTokenCreate issues a new token for the channel.
+	PeerChannelTokenCreate(ctx context.Context, channelID string, req PeerChannelTokenCreate) (*PeerChannelToken, error)
+}
+
+// PeerChannelWriter posts messages to a peer channel.
+type PeerChannelWriter interface {
+	// PeerChannelMessageCreate will post v encoded as JSON to the channel.
+	PeerChannelMessageCreate(ctx context.Context, channel PeerChannelData, v interface{}) (*PeerChannelMessage, error)
+}
+
+// PeerChannelReader reads messages from a peer channel.
+type PeerChannelReader interface {
+	// PeerChannelMessages returns the messages matching the args, oldest first.
+	PeerChannelMessages(ctx context.Context, channel PeerChannelData, args PeerChannelMessagesArgs) ([]PeerChannelMessage, error)
+}
+
+// Data returns the PeerChannelData for the channel with the token, the host is read from
+// the Href which is expected to be served from PeerChannelPath.
+func (p PeerChannel) Data(token string) PeerChannelData {
+	return PeerChannelData{
+		Host:      strings.TrimSuffix(p.Href, PeerChannelPath+"/"+p.ID),
+		Path:      PeerChannelPath,
+		ChannelID: p.ID,
+		Token:     token,
+	}
+}
+
+// Peer channel payment message types.
+const (
+	// PeerChannelMessageStatus is posted when the invoice for a payment changes state.
+	PeerChannelMessageStatus = "status"
+	// PeerChannelMessageProof is posted when a merkle proof is received for the payment transaction.
+	PeerChannelMessageProof = "proof"
+)
+
+// PeerChannelPaymentMessage is posted to the peer channel opened for a payment.
+type PeerChannelPaymentMessage struct {
+	// Type is PeerChannelMessageStatus or PeerChannelMessageProof.
+	Type      string       `json:"type" enums:"status,proof"`
+	PaymentID string       `json:"paymentId"`
+	TxID      string       `json:"txid"`
+	State     InvoiceState `json:"state"`
+	// Proof is the merkle proof envelope as received from mAPI, set for PeerChannelMessageProof.
+	Proof     *envelope.JSONEnvelope `json:"proof,omitempty"`
+	Timestamp time.Time              `json:"timestamp" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// ErrPaymentChannelNotFound is returned when no peer channel has been opened for a payment.
+var ErrPaymentChannelNotFound = errors.New("payment channel not found")
+
+// PaymentChannel is the peer channel opened for a payment.
+type PaymentChannel struct {
+	PaymentID string
+	TxID      string
+	// Channel contains the owner token which can write to the channel, it must not be shared with the payer.
+	Channel PeerChannelData
+}
+
+// PaymentChannelReader reads the peer channels opened for payments.
+type PaymentChannelReader interface {
+	// PaymentChannel returns the channel opened for the payment txid or ErrPaymentChannelNotFound.
+	PaymentChannel(ctx context.Context, txID string) (*PaymentChannel, error)
+}
+
+// PaymentChannelWriter stores the peer channels opened for payments.
+type PaymentChannelWriter interface {
+	// PaymentChannelCreate will store the channel against the payment txid.
+	PaymentChannelCreate(ctx context.Context, req PaymentChannel) error
+}
+
+// PaymentChannelReaderWriter combines the reader and writer interfaces.
+type PaymentChannelReaderWriter interface {
+	PaymentChannelReader
+	PaymentChannelWriter
+}
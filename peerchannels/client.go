@@ -0,0 +1,206 @@
+// Package peerchannels contains a client for peer channels, used to exchange follow up
+// messages such as payment status updates and merkle proofs after a PaymentACK, along
+// with a minimal in-process Server implementing the same API.
+//
+// The API follows the SPV Channels paths, channels are created under the account with
+// an account token and read from and written to with the channel tokens. Reads can
+// long-poll by supplying a wait duration.
+package peerchannels
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Config contains the settings used to connect to a peer channels server.
+type Config struct {
+	// Host is the base url of the server including the scheme, ie https://peerchannels:25009.
+	Host string
+	// Token is the account token used to create channels and tokens.
+	Token string
+	// Secure if true will validate the server TLS certificates.
+	Secure bool
+	// Timeout is the maximum time to wait for the server to respond, defaults to 30 seconds.
+	// Long-polling reads wait for this plus the wait duration.
+	Timeout time.Duration
+}
+
+// Client calls a peer channels server, it implements dpp.PeerChannelCreator, dpp.PeerChannelWriter,
+// dpp.PeerChannelReader and dpp.PeerChannelNotifier.
+type Client struct {
+	cfg    *Config
+	client *http.Client
+}
+
+// NewClient will setup and return a new peer channels client.
+func NewClient(cfg *Config) *Client {
+	c := *cfg
+	if c.Timeout == 0 {
+		c.Timeout = 30 * time.Second
+	}
+	return &Client{
+		cfg: &c,
+		client: &http.Client{
+			Transport: &http.Transport{
+				// nolint:gosec // verification is disabled deliberately when not running securely.
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: !c.Secure},
+			},
+		},
+	}
+}
+
+// PeerChannelData returns the details a reader or writer needs to use the channel with the token.
+func (c *Client) PeerChannelData(channelID, token string) dpp.PeerChannelData {
+	return dpp.PeerChannelData{
+		Host:      strings.TrimSuffix(c.cfg.Host, "/"),
+		Path:      dpp.PeerChannelPath,
+		ChannelID: channelID,
+		Token:     token,
+	}
+}
+
+// PeerChannelCreate will create a channel using the account token.
+func (c *Client) PeerChannelCreate(ctx context.Context, req dpp.PeerChannelCreate) (*dpp.PeerChannel, error) {
+	var ch dpp.PeerChannel
+	if err := c.do(ctx, http.MethodPost, c.accountURL(""), c.cfg.Token, 0, req, &ch); err != nil {
+		return nil, errors.Wrap(err, "failed to create peer channel")
+	}
+	return &ch, nil
+}
+
+// PeerChannelTokenCreate will issue a token for the channel using the account token.
+func (c *Client) PeerChannelTokenCreate(ctx context.Context, channelID string, req dpp.PeerChannelTokenCreate) (*dpp.PeerChannelToken, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	var t dpp.PeerChannelToken
+	if err := c.do(ctx, http.MethodPost, c.accountURL("/"+url.PathEscape(channelID)+"/api-token"), c.cfg.Token, 0, req, &t); err != nil {
+		return nil, errors.Wrapf(err, "failed to create token for peer channel %s", channelID)
+	}
+	return &t, nil
+}
+
+// PeerChannelMessageCreate will post v to the channel as JSON.
+func (c *Client) PeerChannelMessageCreate(ctx context.Context, channel dpp.PeerChannelData, v interface{}) (*dpp.PeerChannelMessage, error) {
+	var msg dpp.PeerChannelMessage
+	if err := c.do(ctx, http.MethodPost, channel.URL(), channel.Token, 0, v, &msg); err != nil {
+		return nil, errors.Wrapf(err, "failed to post to peer channel %s", channel.ChannelID)
+	}
+	return &msg, nil
+}
+
+// PeerChannelNotify will post msg to the channel, it satisfies dpp.PeerChannelNotifier.
+func (c *Client) PeerChannelNotify(ctx context.Context, channel dpp.PeerChannelData, msg interface{}) error {
+	_, err := c.PeerChannelMessageCreate(ctx, channel, msg)
+	return err
+}
+
+// PeerChannelMessages will read the messages after args.After, long-polling for up to
+// args.Wait if there are none.
+func (c *Client) PeerChannelMessages(ctx context.Context, channel dpp.PeerChannelData, args dpp.PeerChannelMessagesArgs) ([]dpp.PeerChannelMessage, error) {
+	q := url.Values{}
+	q.Set("after", strconv.FormatUint(args.After, 10))
+	if args.Wait > 0 {
+		q.Set("wait", args.Wait.String())
+	}
+	var msgs []dpp.PeerChannelMessage
+	if err := c.do(ctx, http.MethodGet, channel.URL()+"?"+q.Encode(), channel.Token, args.Wait, nil, &msgs); err != nil {
+		return nil, errors.Wrapf(err, "failed to read peer channel %s", channel.ChannelID)
+	}
+	return msgs, nil
+}
+
+// Stream will long-poll the channel calling fn with each message after the sequence in
+// order, it returns when the context is cancelled or fn or a read returns an error.
+func (c *Client) Stream(ctx context.Context, channel dpp.PeerChannelData, after uint64, wait time.Duration, fn func(dpp.PeerChannelMessage) error) error {
+	for {
+		msgs, err := c.PeerChannelMessages(ctx, channel, dpp.PeerChannelMessagesArgs{After: after, Wait: wait})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		for _, m := range msgs {
+			if err := fn(m); err != nil {
+				return err
+			}
+			after = m.Sequence
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) accountURL(path string) string {
+	return strings.TrimSuffix(c.cfg.Host, "/") + "/api/v1/account/channel" + path
+}
+
+// do will send the request with the bearer token, marshalling req to JSON if supplied, and
+// unmarshal a successful response into out. The request can take up to wait longer than the timeout.
+func (c *Client) do(ctx context.Context, method, url, token string, wait time.Duration, req, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout+wait)
+	defer cancel()
+	var body io.Reader
+	if req != nil {
+		bb, err := json.Marshal(req)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode request")
+		}
+		body = bytes.NewReader(bb)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return errors.Wrap(err, "failed to create request")
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	bb, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response")
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		errResp := ErrResponse{StatusCode: resp.StatusCode}
+		if err := json.Unmarshal(bb, &errResp); err != nil || errResp.Message == "" {
+			errResp.Message = strings.TrimSpace(string(bb))
+		}
+		return errResp
+	}
+	if out == nil || len(bb) == 0 {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(bb, out), "failed to decode response")
+}
+
+// ErrResponse is returned when the server responds with an error status code.
+type ErrResponse struct {
+	StatusCode int    `json:"-"`
+	Title      string `json:"title"`
+	Message    string `json:"message"`
+}
+
+// Error satisfies the error interface.
+func (e ErrResponse) Error() string {
+	return fmt.Sprintf("peer channels responded with status %d: %s", e.StatusCode, e.Message)
+}
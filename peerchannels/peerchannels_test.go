@@ -0,0 +1,107 @@
+package peerchannels_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/peerchannels"
+)
+
+func setup(t *testing.T) *peerchannels.Client {
+	srv := httptest.NewServer(peerchannels.NewServer("account-token"))
+	t.Cleanup(srv.Close)
+	return peerchannels.NewClient(&peerchannels.Config{Host: srv.URL, Token: "account-token"})
+}
+
+func TestClient_Messages(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	c := setup(t)
+	ch, err := c.PeerChannelCreate(ctx, dpp.PeerChannelCreate{})
+	is.NoErr(err)
+	is.Equal(len(ch.AccessTokens), 1)
+	owner := c.PeerChannelData(ch.ID, ch.AccessTokens[0].Token)
+	is.Equal(owner.URL(), ch.Href)
+
+	read, err := c.PeerChannelTokenCreate(ctx, ch.ID, dpp.PeerChannelTokenCreate{Description: "payer", CanRead: true})
+	is.NoErr(err)
+	payer := c.PeerChannelData(ch.ID, read.Token)
+
+	msg, err := c.PeerChannelMessageCreate(ctx, owner, map[string]string{"status": "paid"})
+	is.NoErr(err)
+	is.Equal(msg.Sequence, uint64(1))
+	_, err = c.PeerChannelMessageCreate(ctx, owner, map[string]string{"status": "confirmed"})
+	is.NoErr(err)
+
+	msgs, err := c.PeerChannelMessages(ctx, payer, dpp.PeerChannelMessagesArgs{})
+	is.NoErr(err)
+	is.Equal(len(msgs), 2)
+	is.Equal(string(msgs[0].Payload), `{"status":"paid"}`)
+	msgs, err = c.PeerChannelMessages(ctx, payer, dpp.PeerChannelMessagesArgs{After: 1})
+	is.NoErr(err)
+	is.Equal(len(msgs), 1)
+	is.Equal(msgs[0].Sequence, uint64(2))
+	msgs, err = c.PeerChannelMessages(ctx, payer, dpp.PeerChannelMessagesArgs{After: 2})
+	is.NoErr(err)
+	is.Equal(len(msgs), 0)
+
+	// the read only token cannot write.
+	_, err = c.PeerChannelMessageCreate(ctx, payer, map[string]string{"status": "spoofed"})
+	var errResp peerchannels.ErrResponse
+	is.True(errors.As(err, &errResp))
+	is.Equal(errResp.StatusCode, http.StatusForbidden)
+
+	_, err = c.PeerChannelMessages(ctx, c.PeerChannelData(ch.ID, "guess"), dpp.PeerChannelMessagesArgs{})
+	is.True(errors.As(err, &errResp))
+	is.Equal(errResp.StatusCode, http.StatusUnauthorized)
+}
+
+func TestClient_AccountToken(t *testing.T) {
+	is := is.New(t)
+	srv := httptest.NewServer(peerchannels.NewServer("account-token"))
+	defer srv.Close()
+	c := peerchannels.NewClient(&peerchannels.Config{Host: srv.URL, Token: "wrong"})
+	_, err := c.PeerChannelCreate(context.Background(), dpp.PeerChannelCreate{})
+	var errResp peerchannels.ErrResponse
+	is.True(errors.As(err, &errResp))
+	is.Equal(errResp.StatusCode, http.StatusUnauthorized)
+}
+
+func TestClient_Stream(t *testing.T) {
+	is := is.New(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c := setup(t)
+	ch, err := c.PeerChannelCreate(ctx, dpp.PeerChannelCreate{PublicRead: true})
+	is.NoErr(err)
+	owner := c.PeerChannelData(ch.ID, ch.AccessTokens[0].Token)
+
+	got := make(chan dpp.PeerChannelMessage)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.Stream(ctx, c.PeerChannelData(ch.ID, ""), 0, time.Second, func(m dpp.PeerChannelMessage) error {
+			got <- m
+			return nil
+		})
+	}()
+	for i := 1; i <= 3; i++ {
+		// the reader is long-polling when each message is posted.
+		time.Sleep(20 * time.Millisecond)
+		is.NoErr(c.PeerChannelNotify(ctx, owner, map[string]int{"n": i}))
+		m := <-got
+		is.Equal(m.Sequence, uint64(i))
+		var body map[string]int
+		is.NoErr(json.Unmarshal(m.Payload, &body))
+		is.Equal(body["n"], i)
+	}
+	cancel()
+	is.True(errors.Is(<-done, context.Canceled))
+}
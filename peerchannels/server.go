@@ -0,0 +1,299 @@
+package peerchannels
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Server paths.
+const (
+	pathAccountChannel = "/api/v1/account/channel"
+	pathTokenSuffix    = "/api-token"
+)
+
+// MaxWait is the longest a reader can long-poll for messages.
+const MaxWait = time.Minute
+
+// maxMessageBytes is the largest message body accepted.
+const maxMessageBytes = 1 << 20
+
+type channel struct {
+	dpp.PeerChannel
+	messages []dpp.PeerChannelMessage
+	// posted is closed and replaced when a message is posted to wake long-polling readers.
+	posted chan struct{}
+}
+
+// Server is a minimal in-process peer channels server implementing the API used by Client,
+// channels and messages are kept in memory. It is an http.Handler, serve it with
+// http.ListenAndServe or httptest.NewServer.
+//
+// Channels are created with the account token, messages are written and read with
+// a channel token or without one if the channel is public.
+type Server struct {
+	mu           sync.Mutex
+	accountToken string
+	channels     map[string]*channel
+	now          func() time.Time
+}
+
+// NewServer will return a new server with no channels, accountToken must be supplied as
+// a bearer token to create channels and tokens.
+func NewServer(accountToken string) *Server {
+	return &Server{
+		accountToken: accountToken,
+		channels:     map[string]*channel{},
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// ServeHTTP routes requests to the account and channel endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == pathAccountChannel:
+		s.handleChannelCreate(w, r)
+	case strings.HasPrefix(r.URL.Path, pathAccountChannel+"/") && strings.HasSuffix(r.URL.Path, pathTokenSuffix):
+		s.handleTokenCreate(w, r,
+			strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, pathAccountChannel+"/"), pathTokenSuffix))
+	case strings.HasPrefix(r.URL.Path, dpp.PeerChannelPath+"/"):
+		channelID := strings.TrimPrefix(r.URL.Path, dpp.PeerChannelPath+"/")
+		switch r.Method {
+		case http.MethodPost:
+			s.handleMessageCreate(w, r, channelID)
+		case http.MethodGet:
+			s.handleMessages(w, r, channelID)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (s *Server) handleChannelCreate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.isAccount(r) {
+		writeError(w, http.StatusUnauthorized, "invalid account token")
+		return
+	}
+	var req dpp.PeerChannelCreate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid channel body")
+		return
+	}
+	ch := &channel{
+		PeerChannel: dpp.PeerChannel{
+			ID:          randomHex(16),
+			PublicRead:  req.PublicRead,
+			PublicWrite: req.PublicWrite,
+			Sequenced:   true,
+			CreatedAt:   s.now(),
+		},
+		posted: make(chan struct{}),
+	}
+	ch.Href = requestHost(r) + dpp.PeerChannelPath + "/" + ch.ID
+	ch.AccessTokens = []dpp.PeerChannelToken{newToken(dpp.PeerChannelTokenCreate{
+		Description: "owner", CanRead: true, CanWrite: true,
+	})}
+	s.mu.Lock()
+	s.channels[ch.ID] = ch
+	resp := copyChannel(ch)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) handleTokenCreate(w http.ResponseWriter, r *http.Request, channelID string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.isAccount(r) {
+		writeError(w, http.StatusUnauthorized, "invalid account token")
+		return
+	}
+	var req dpp.PeerChannelTokenCreate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid token body")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, ok := s.channels[channelID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "channel not found")
+		return
+	}
+	t := newToken(req)
+	ch.AccessTokens = append(ch.AccessTokens, t)
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) handleMessageCreate(w http.ResponseWriter, r *http.Request, channelID string) {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "messages must be application/json")
+		return
+	}
+	bb, err := io.ReadAll(io.LimitReader(r.Body, maxMessageBytes+1))
+	if err != nil || len(bb) > maxMessageBytes || !json.Valid(bb) {
+		writeError(w, http.StatusBadRequest, "invalid message body")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ch, status := s.authorise(r, channelID, false)
+	if ch == nil {
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+	msg := dpp.PeerChannelMessage{
+		Sequence:    uint64(len(ch.messages) + 1),
+		Received:    s.now(),
+		ContentType: "application/json",
+		Payload:     bb,
+	}
+	ch.messages = append(ch.messages, msg)
+	close(ch.posted)
+	ch.posted = make(chan struct{})
+	writeJSON(w, http.StatusCreated, msg)
+}
+
+func (s *Server) handleMessages(w http.ResponseWriter, r *http.Request, channelID string) {
+	var args dpp.PeerChannelMessagesArgs
+	if v := r.URL.Query().Get("after"); v != "" {
+		after, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "after must be a sequence number")
+			return
+		}
+		args.After = after
+	}
+	if v := r.URL.Query().Get("wait"); v != "" {
+		wait, err := time.ParseDuration(v)
+		if err != nil || wait < 0 {
+			writeError(w, http.StatusBadRequest, "wait must be a duration, ie 30s")
+			return
+		}
+		if wait > MaxWait {
+			wait = MaxWait
+		}
+		args.Wait = wait
+	}
+	timer := time.NewTimer(args.Wait)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		ch, status := s.authorise(r, channelID, true)
+		if ch == nil {
+			s.mu.Unlock()
+			writeError(w, status, http.StatusText(status))
+			return
+		}
+		msgs := make([]dpp.PeerChannelMessage, 0)
+		if args.After < uint64(len(ch.messages)) {
+			msgs = append(msgs, ch.messages[args.After:]...)
+		}
+		posted := ch.posted
+		s.mu.Unlock()
+		if len(msgs) > 0 || args.Wait == 0 {
+			writeJSON(w, http.StatusOK, msgs)
+			return
+		}
+		select {
+		case <-posted:
+		case <-timer.C:
+			args.Wait = 0
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// authorise returns the channel if the request token grants read or write access, or the
+// status code to respond with if not. It must be called with the lock held.
+func (s *Server) authorise(r *http.Request, channelID string, read bool) (*channel, int) {
+	ch, ok := s.channels[channelID]
+	if !ok {
+		return nil, http.StatusNotFound
+	}
+	if (read && ch.PublicRead) || (!read && ch.PublicWrite) {
+		return ch, 0
+	}
+	token := dpp.BearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		return nil, http.StatusUnauthorized
+	}
+	for _, t := range ch.AccessTokens {
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) != 1 {
+			continue
+		}
+		if (read && t.CanRead) || (!read && t.CanWrite) {
+			return ch, 0
+		}
+		return nil, http.StatusForbidden
+	}
+	return nil, http.StatusUnauthorized
+}
+
+func (s *Server) isAccount(r *http.Request) bool {
+	token := dpp.BearerToken(r.Header.Get("Authorization"))
+	return token != "" && subtle.ConstantTimeCompare([]byte(s.accountToken), []byte(token)) == 1
+}
+
+func newToken(req dpp.PeerChannelTokenCreate) dpp.PeerChannelToken {
+	return dpp.PeerChannelToken{
+		ID:          randomHex(8),
+		Token:       randomHex(32),
+		Description: req.Description,
+		CanRead:     req.CanRead,
+		CanWrite:    req.CanWrite,
+	}
+}
+
+func copyChannel(ch *channel) dpp.PeerChannel {
+	c := ch.PeerChannel
+	c.AccessTokens = append([]dpp.PeerChannelToken{}, ch.AccessTokens...)
+	return c
+}
+
+func randomHex(n int) string {
+	bb := make([]byte, n)
+	if _, err := rand.Read(bb); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(bb)
+}
+
+func requestHost(r *http.Request) string {
+	if r.TLS != nil {
+		return "https://" + r.Host
+	}
+	return "http://" + r.Host
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, ErrResponse{Title: http.StatusText(status), Message: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,80 @@
+package dpp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// proofTokenBytes is the number of random bytes in a proof callback token.
+const proofTokenBytes = 32
+
+// ProofToken is the bearer token issued when a payment transaction is broadcast, mAPI
+// sends it back with every merkle proof callback for the transaction.
+type ProofToken struct {
+	// TxID is the broadcast transaction.
+	TxID string `json:"txid"`
+	// PaymentID is the invoice the transaction paid.
+	PaymentID string `json:"paymentId"`
+	// Token is the bearer token proof callbacks must supply.
+	Token string `json:"token"`
+}
+
+// NewProofToken returns a random hex encoded proof callback token.
+func NewProofToken() (string, error) {
+	bb := make([]byte, proofTokenBytes)
+	if _, err := rand.Read(bb); err != nil {
+		return "", errors.Wrap(err, "failed to generate proof token")
+	}
+	return hex.EncodeToString(bb), nil
+}
+
+// Matches returns true if the token equals the issued token, compared in constant time.
+func (p ProofToken) Matches(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(p.Token), []byte(token)) == 1
+}
+
+// BearerToken returns the token from an Authorization header value in the
+// format "Bearer <token>", or an empty string if it is not a bearer token.
+func BearerToken(header string) string {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+// ErrProofTokenNotFound is returned when no proof token has been issued for a txid.
+var ErrProofTokenNotFound = errors.New("proof token not found")
+
+// ProofTokenError is returned when a proof callback does not supply the token issued for its transaction.
+type ProofTokenError struct {
+	TxID string
+}
+
+// Error satisfies the error interface.
+func (e ProofTokenError) Error() string {
+	return fmt.Sprintf("missing or invalid proof token for tx %s", e.TxID)
+}
+
+// NotAuthenticated indicates the proof callback could not be authenticated.
+func (e ProofTokenError) NotAuthenticated() bool {
+	return true
+}
+
+// ProofTokenReader reads issued proof tokens.
+type ProofTokenReader interface {
+	// ProofToken returns the token issued for a txid or ErrProofTokenNotFound.
+	ProofToken(ctx context.Context, txID string) (*ProofToken, error)
+}
+
+// ProofTokenWriter stores issued proof tokens.
+type ProofTokenWriter interface {
+	// ProofTokenCreate will store the token, replacing any token already issued for the txid.
+	ProofTokenCreate(ctx context.Context, req ProofToken) error
+}
@@ -0,0 +1,48 @@
+package dpp_test
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestBearerToken(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		exp    string
+	}{
+		"bearer token should be returned": {
+			header: "Bearer abc123",
+			exp:    "abc123",
+		}, "scheme should be case insensitive": {
+			header: "bearer abc123",
+			exp:    "abc123",
+		}, "basic auth should be ignored": {
+			header: "Basic abc123",
+		}, "empty bearer should be ignored": {
+			header: "Bearer ",
+		}, "empty header should be ignored": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is.New(t).Equal(dpp.BearerToken(test.header), test.exp)
+		})
+	}
+}
+
+func TestProofToken_Matches(t *testing.T) {
+	is := is.New(t)
+	token, err := dpp.NewProofToken()
+	is.NoErr(err)
+	is.Equal(len(token), 64)
+	other, err := dpp.NewProofToken()
+	is.NoErr(err)
+	is.True(token != other)
+
+	pt := dpp.ProofToken{TxID: "abc", Token: token}
+	is.True(pt.Matches(token))
+	is.True(!pt.Matches(other))
+	is.True(!pt.Matches(""))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/libsv/go-bc"
 	"github.com/libsv/go-bk/envelope"
@@ -17,6 +18,14 @@ type ProofCreateArgs struct {
 	// TxID will be used to validate the proof envelope.
 	TxID             string `json:"txId" param:"txid"`
 	PaymentReference string `query:"i"`
+	// Token is the bearer token issued when the transaction was broadcast. It is read from
+	// the token query parameter, transports should fall back to the Authorization header
+	// using BearerToken.
+	Token string `query:"token"`
+	// Outbox is set by the server, not the sender, to the webhooks describing the proof. The
+	// ProofsWriter must store them in the same transaction as the proof so they are queued only
+	// if the proof is stored.
+	Outbox []OutboxMessage `json:"-"`
 }
 
 // ProofWrapper represents a mapi callback payload for a merkleproof.
@@ -78,6 +87,28 @@ func (p ProofWrapper) Validate(args ProofCreateArgs) error {
 	return vl.Err()
 }
 
+// ProofsArgs are used to filter stored proofs.
+type ProofsArgs struct {
+	// TxID returns proofs for this transaction.
+	TxID string `query:"txid"`
+	// From returns proofs received at or after this time.
+	From time.Time `query:"from"`
+	// To returns proofs received before this time.
+	To time.Time `query:"to"`
+}
+
+// ProofRecord is a merkle proof envelope that has been stored.
+type ProofRecord struct {
+	// TxID is the transaction the proof is for.
+	TxID string `json:"txid"`
+	// PaymentReference is the reference supplied with the proof callback, if any.
+	PaymentReference string `json:"paymentReference"`
+	// Envelope is the proof as it was received.
+	Envelope envelope.JSONEnvelope `json:"envelope"`
+	// CreatedAt is the time the proof was stored.
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
 // ProofsService enforces business rules and validation when handling merkle proofs.
 type ProofsService interface {
 	// Create will store a JSONEnvelope that contains a merkleproof. The envelope should
@@ -91,3 +122,9 @@ type ProofsWriter interface {
 	// ProofCreate can be used to persist a merkle proof in TSC format.
 	ProofCreate(ctx context.Context, args ProofCreateArgs, req envelope.JSONEnvelope) error
 }
+
+// ProofsReader is used to read stored proofs from a data store.
+type ProofsReader interface {
+	// Proofs returns all proofs matching the args, oldest first.
+	Proofs(ctx context.Context, args ProofsArgs) ([]ProofRecord, error)
+}
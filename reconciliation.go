@@ -0,0 +1,124 @@
+package dpp
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// ReconciliationStatus is the outcome of reconciling a single invoice or proof.
+type ReconciliationStatus string
+
+// Supported reconciliation statuses.
+const (
+	// ReconciliationStatusReconciled means the invoice was paid in full and the payment confirmed.
+	ReconciliationStatusReconciled ReconciliationStatus = "reconciled"
+	// ReconciliationStatusUnpaid means no payment has been received for the invoice.
+	ReconciliationStatusUnpaid ReconciliationStatus = "unpaid"
+	// ReconciliationStatusUnderpaid means less than the requested amount was received.
+	ReconciliationStatusUnderpaid ReconciliationStatus = "underpaid"
+	// ReconciliationStatusOverpaid means more than the requested amount was received.
+	ReconciliationStatusOverpaid ReconciliationStatus = "overpaid"
+	// ReconciliationStatusUnconfirmed means the invoice was paid in full but no merkle proof has been received.
+	ReconciliationStatusUnconfirmed ReconciliationStatus = "paid_unconfirmed"
+	// ReconciliationStatusProofWithoutInvoice means a merkle proof was received for a transaction
+	// that does not relate to any known payment or invoice.
+	ReconciliationStatusProofWithoutInvoice ReconciliationStatus = "proof_without_invoice"
+)
+
+// ReconciliationArgs define the period to reconcile.
+type ReconciliationArgs struct {
+	// From includes invoices and proofs created at or after this time.
+	From time.Time `query:"from"`
+	// To includes invoices and proofs created before this time.
+	To time.Time `query:"to"`
+}
+
+// Validate will ensure that the ReconciliationArgs are supplied and correct.
+func (r ReconciliationArgs) Validate() error {
+	return validator.New().
+		Validate("from", validator.NotEmpty(r.From)).
+		Validate("to", validator.NotEmpty(r.To), validator.DateAfter(r.To, r.From)).
+		Err()
+}
+
+// ReconciliationEntry is a single line in a reconciliation report.
+type ReconciliationEntry struct {
+	// Status is the outcome of reconciling this entry.
+	Status ReconciliationStatus `json:"status"`
+	// PaymentID is the invoice the entry relates to, empty for proofs without an invoice.
+	PaymentID string `json:"paymentId"`
+	// InvoiceState is the state of the invoice at the time the report was produced.
+	InvoiceState InvoiceState `json:"invoiceState,omitempty"`
+	// Expected is the number of satoshis requested by the invoice.
+	Expected uint64 `json:"expected"`
+	// Received is the number of satoshis paid to the invoice destinations.
+	Received uint64 `json:"received"`
+	// TxIDs are the payment transactions for the invoice, or the proof transaction.
+	TxIDs []string `json:"txids"`
+	// Confirmed is true if a merkle proof has been received for every payment transaction.
+	Confirmed bool `json:"confirmed"`
+	// CreatedAt is the time the invoice, or proof, was created.
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// Difference returns the received amount minus the expected amount.
+func (r ReconciliationEntry) Difference() int64 {
+	return int64(r.Received) - int64(r.Expected)
+}
+
+// ReconciliationReport contains the outcome of reconciling invoices, payments and proofs over a period.
+type ReconciliationReport struct {
+	From        time.Time                    `json:"from" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	To          time.Time                    `json:"to" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	GeneratedAt time.Time                    `json:"generatedAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	Totals      map[ReconciliationStatus]int `json:"totals"`
+	Entries     []ReconciliationEntry        `json:"entries"`
+}
+
+// WriteJSON will write the report to w as JSON.
+func (r ReconciliationReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(r), "failed to write reconciliation report")
+}
+
+// WriteCSV will write the report entries to w as CSV with a header row,
+// multiple txids are separated by a space.
+func (r ReconciliationReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"status", "paymentId", "invoiceState", "expected", "received", "difference", "txids", "confirmed", "createdAt",
+	}); err != nil {
+		return errors.Wrap(err, "failed to write reconciliation report header")
+	}
+	for _, e := range r.Entries {
+		if err := cw.Write([]string{
+			string(e.Status),
+			e.PaymentID,
+			string(e.InvoiceState),
+			strconv.FormatUint(e.Expected, 10),
+			strconv.FormatUint(e.Received, 10),
+			strconv.FormatInt(e.Difference(), 10),
+			strings.Join(e.TxIDs, " "),
+			strconv.FormatBool(e.Confirmed),
+			e.CreatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return errors.Wrapf(err, "failed to write reconciliation entry for %s", e.PaymentID)
+		}
+	}
+	cw.Flush()
+	return errors.Wrap(cw.Error(), "failed to write reconciliation report")
+}
+
+// ReconciliationService joins invoices, payments and proofs to produce a reconciliation report.
+type ReconciliationService interface {
+	Reconcile(ctx context.Context, args ReconciliationArgs) (*ReconciliationReport, error)
+}
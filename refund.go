@@ -0,0 +1,159 @@
+package dpp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// RefundArgs identifies the payment to refund.
+type RefundArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure that the RefundArgs are supplied and correct.
+func (r RefundArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(r.PaymentID)).
+		Err()
+}
+
+// RefundCreate contains the information required to refund a payment.
+type RefundCreate struct {
+	// Amount is the number of satoshis to return, it cannot exceed the amount paid less
+	// any previous refunds.
+	Amount uint64 `json:"amount" example:"1000"`
+	// Reason explains why the refund was made, it is sent to the payer.
+	// Maximum length is 250 characters.
+	Reason string `json:"reason" example:"goods returned"`
+}
+
+// Validate will ensure the RefundCreate is valid.
+func (r RefundCreate) Validate() error {
+	return validator.New().
+		Validate("amount", validator.PositiveUInt64(r.Amount)).
+		Validate("reason", validator.StrLength(r.Reason, 1, 250)).
+		Err()
+}
+
+// RefundStatus describes whether a refund transaction has been broadcast.
+type RefundStatus string
+
+// Supported refund statuses.
+const (
+	// RefundStatusPending means the refund tx has been signed and stored but not yet broadcast.
+	// Pending refunds count against the amount left to refund as they may have been broadcast.
+	RefundStatusPending RefundStatus = "pending"
+	// RefundStatusBroadcast means the refund tx was accepted by the TransactionBroadcaster.
+	RefundStatusBroadcast RefundStatus = "broadcast"
+	// RefundStatusFailed means the TransactionBroadcaster rejected the refund tx.
+	RefundStatusFailed RefundStatus = "failed"
+)
+
+// Refund is a refund made for a payment.
+type Refund struct {
+	// PaymentID is the invoice that was refunded.
+	PaymentID string `json:"paymentId"`
+	// PaymentTxID is the transaction of the payment that was refunded.
+	PaymentTxID string `json:"paymentTxid"`
+	// TxID is the refund transaction.
+	TxID string `json:"txid"`
+	// Amount is the number of satoshis refunded.
+	Amount uint64 `json:"amount"`
+	// Reason explains why the refund was made.
+	Reason string `json:"reason"`
+	// RefundTo is the paymail the refund was paid to.
+	RefundTo string `json:"refundTo"`
+	// Reference is the paymail P2P reference the refund was sent with, if any.
+	Reference string `json:"reference,omitempty"`
+	// Status is pending until the refund tx has been broadcast.
+	Status RefundStatus `json:"status" enums:"pending,broadcast,failed"`
+	// Notified is true if the payer was notified over their peer channel.
+	Notified bool `json:"notified"`
+	// CreatedAt is the time the refund was signed.
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// RefundNotification is posted to the payers peer channel when a refund is broadcast.
+type RefundNotification struct {
+	PaymentID string `json:"paymentId"`
+	TxID      string `json:"txid"`
+	Amount    uint64 `json:"amount"`
+	Reason    string `json:"reason"`
+}
+
+// RefundExceededError is returned when a refund is more than the amount left to refund.
+type RefundExceededError struct {
+	PaymentID string
+	// Amount is the refund requested.
+	Amount uint64
+	// Remaining is the amount of the payment not yet refunded.
+	Remaining uint64
+}
+
+// Error satisfies the error interface.
+func (e RefundExceededError) Error() string {
+	return fmt.Sprintf("refund of %d satoshis exceeds the %d satoshis remaining for payment %s", e.Amount, e.Remaining, e.PaymentID)
+}
+
+// Conflict indicates the refund conflicts with refunds already made.
+func (e RefundExceededError) Conflict() bool {
+	return true
+}
+
+// ErrRefundNotFound is returned by stores when a refund does not exist.
+var ErrRefundNotFound = errors.New("refund not found")
+
+// ErrPaymentNotFound is returned when no accepted payment exists for an invoice.
+var ErrPaymentNotFound = errors.New("payment not found")
+
+// RefundService refunds accepted payments.
+type RefundService interface {
+	// RefundCreate will pay the amount back to the payments RefundTo paymail.
+	RefundCreate(ctx context.Context, args RefundArgs, req RefundCreate) (*Refund, error)
+	// Refunds returns the refunds made for a payment, oldest first.
+	Refunds(ctx context.Context, args RefundArgs) ([]Refund, error)
+}
+
+// RefundReader reads stored refunds.
+type RefundReader interface {
+	// Refunds returns the refunds made for a payment, oldest first.
+	Refunds(ctx context.Context, args RefundArgs) ([]Refund, error)
+}
+
+// RefundWriter stores refunds.
+type RefundWriter interface {
+	// RefundCreate will store a pending refund before it is broadcast.
+	RefundCreate(ctx context.Context, req Refund) error
+	// RefundUpdate will replace the stored refund with the same PaymentID and TxID, it is
+	// used to record the outcome of the broadcast.
+	RefundUpdate(ctx context.Context, req Refund) error
+}
+
+// RefundReaderWriter combines the reader and writer interfaces.
+type RefundReaderWriter interface {
+	RefundReader
+	RefundWriter
+}
+
+// RefundSigner is implemented by the merchant wallet to fund and sign a transaction
+// paying the outputs, it should add inputs and change as required.
+type RefundSigner interface {
+	RefundTx(ctx context.Context, outputs []Output) (*bt.Tx, error)
+}
+
+// TransactionBroadcaster will broadcast a transaction to the network. An error should only
+// be returned when the transaction was not accepted, refunds are marked as failed and the
+// amount can be refunded again.
+type TransactionBroadcaster interface {
+	Broadcast(ctx context.Context, tx *bt.Tx) error
+}
+
+// PeerChannelNotifier will post a JSON message to a peer channel.
+type PeerChannelNotifier interface {
+	PeerChannelNotify(ctx context.Context, channel PeerChannelData, msg interface{}) error
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// audit will append a record for v to the log, a rawBody is recorded byte for byte.
+func audit(ctx context.Context, aw dpp.AuditWriter, typ dpp.AuditEventType, paymentID, txID string, v interface{}) error {
+	payload, ok := v.(rawBody)
+	if !ok {
+		var err error
+		if payload, err = dpp.AuditPayload(v); err != nil {
+			return err
+		}
+	}
+	if _, err := aw.AuditAppend(ctx, dpp.AuditRecord{
+		Type:      typ,
+		PaymentID: paymentID,
+		TxID:      txID,
+		Payload:   payload,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to write %s audit entry", typ)
+	}
+	return nil
+}
+
+// rawBody is a request body to be recorded as it was received.
+type rawBody []byte
+
+// auditBody returns the raw request body if one is in the context, otherwise v.
+func auditBody(ctx context.Context, v interface{}) interface{} {
+	if body, ok := dpp.RawBody(ctx); ok {
+		return rawBody(body)
+	}
+	return v
+}
+
+type paymentRequestAuditor struct {
+	prr dpp.PaymentRequestReader
+	aw  dpp.AuditWriter
+}
+
+// NewPaymentRequestAuditor will wrap a PaymentRequestReader and record every PaymentRequest served
+// in the audit log, a PaymentRequest is not returned if it cannot be recorded. Reads marked with
+// dpp.WithInternalRead are not recorded.
+func NewPaymentRequestAuditor(prr dpp.PaymentRequestReader, aw dpp.AuditWriter) dpp.PaymentRequestReader {
+	return &paymentRequestAuditor{prr: prr, aw: aw}
+}
+
+// PaymentRequest will read and record the PaymentRequest.
+func (p *paymentRequestAuditor) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	pr, err := p.prr.PaymentRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if dpp.InternalRead(ctx) {
+		return pr, nil
+	}
+	if err := audit(ctx, p.aw, dpp.AuditEventPaymentRequest, args.PaymentID, "", pr); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+type paymentAuditor struct {
+	svc dpp.PaymentService
+	aw  dpp.AuditWriter
+}
+
+// NewPaymentAuditor will wrap a PaymentService and record every Payment received, before it is
+// processed, followed by the PaymentACK or error returned. It should be the outermost decorator
+// so payments are recorded as the payer sent them.
+//
+// The raw request body is recorded if the transport supplies it using dpp.WithRawBody.
+func NewPaymentAuditor(svc dpp.PaymentService, aw dpp.AuditWriter) dpp.PaymentService {
+	return &paymentAuditor{svc: svc, aw: aw}
+}
+
+// PaymentCreate will record the payment, pass it to the wrapped service and record the result.
+func (p *paymentAuditor) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := audit(ctx, p.aw, dpp.AuditEventPayment, args.PaymentID, "", auditBody(ctx, req)); err != nil {
+		return nil, err
+	}
+	ack, err := p.svc.PaymentCreate(ctx, args, req)
+	if err != nil {
+		if aErr := audit(ctx, p.aw, dpp.AuditEventPaymentError, args.PaymentID, "", map[string]string{
+			"error": err.Error(),
+		}); aErr != nil {
+			return nil, aErr
+		}
+		return nil, err
+	}
+	if err := audit(ctx, p.aw, dpp.AuditEventPaymentACK, args.PaymentID, ack.TxID, ack); err != nil {
+		return nil, err
+	}
+	return ack, nil
+}
+
+type proofsAuditor struct {
+	svc dpp.ProofsService
+	aw  dpp.AuditWriter
+}
+
+// NewProofsAuditor will wrap a ProofsService and record every proof envelope received before it is processed.
+func NewProofsAuditor(svc dpp.ProofsService, aw dpp.AuditWriter) dpp.ProofsService {
+	return &proofsAuditor{svc: svc, aw: aw}
+}
+
+// Create will record the envelope and pass it to the wrapped service.
+func (p *proofsAuditor) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	if err := audit(ctx, p.aw, dpp.AuditEventProof, args.PaymentReference, args.TxID, auditBody(ctx, req)); err != nil {
+		return err
+	}
+	return p.svc.Create(ctx, args, req)
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestAuditors(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Network:     "mainnet",
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123"},
+	})
+	is.NoErr(err)
+	reject := false
+	payments := service.NewPaymentAuditor(&mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			if reject {
+				return nil, errors.New("store unavailable")
+			}
+			return &dpp.PaymentACK{ID: args.PaymentID, TxID: "tx1"}, nil
+		},
+	}, store)
+	proofs := service.NewProofsAuditor(&mocks.ProofsServiceMock{
+		CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+			return nil
+		},
+	}, store)
+
+	_, err = service.NewPaymentRequestAuditor(store, store).PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	raw := []byte(`{"memo":"exactly as sent",  "merchantData":{}}`)
+	_, err = payments.PaymentCreate(dpp.WithRawBody(ctx, raw), dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{Memo: "exactly as sent"})
+	is.NoErr(err)
+	reject = true
+	_, err = payments.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{Memo: "retry"})
+	is.True(err != nil)
+	is.NoErr(proofs.Create(ctx, dpp.ProofCreateArgs{TxID: "tx1", PaymentReference: "abc123"}, envelope.JSONEnvelope{Payload: "{}"}))
+
+	ee, err := store.AuditEntries(ctx, dpp.AuditArgs{})
+	is.NoErr(err)
+	is.NoErr(dpp.VerifyAuditLog(ee))
+	types := make([]dpp.AuditEventType, 0, len(ee))
+	for _, e := range ee {
+		types = append(types, e.Type)
+		is.Equal(e.PaymentID, "abc123")
+	}
+	is.Equal(types, []dpp.AuditEventType{
+		dpp.AuditEventPaymentRequest,
+		dpp.AuditEventPayment,
+		dpp.AuditEventPaymentACK,
+		dpp.AuditEventPayment,
+		dpp.AuditEventPaymentError,
+		dpp.AuditEventProof,
+	})
+	is.Equal(string(ee[1].Payload), string(raw))
+	is.Equal(ee[2].TxID, "tx1")
+	var p dpp.Payment
+	is.NoErr(json.Unmarshal(ee[3].Payload, &p))
+	is.Equal(p.Memo, "retry")
+	is.Equal(string(ee[4].Payload), `{"error":"store unavailable"}`)
+	is.Equal(ee[5].TxID, "tx1")
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Opt configures the reference services.
+type Opt func(o *options)
+
+type options struct {
+	pub      dpp.EventPublisher
+	webhooks bool
+}
+
+// WithEvents publishes payment lifecycle events to pub, ie an events.Bus.
+//
+// Events are published after the change they describe has been stored so errors
+// returned by subscribers do not fail the request.
+func WithEvents(pub dpp.EventPublisher) Opt {
+	return func(o *options) {
+		o.pub = pub
+	}
+}
+
+// WithWebhooks queues a webhook for each event, a webhooks.Worker delivers them. Webhooks are
+// written atomically with the change they describe, payments and proofs using the Outbox of
+// PaymentCreateArgs and ProofCreateArgs and other events with the invoice transition using
+// InvoiceUpdate.Outbox.
+func WithWebhooks() Opt {
+	return func(o *options) {
+		o.webhooks = true
+	}
+}
+
+func newOptions(opts []Opt) options {
+	o := options{pub: noopPublisher{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// publish will publish the event ignoring subscriber errors.
+func (o options) publish(ctx context.Context, e dpp.Event) {
+	_ = o.pub.Publish(ctx, e)
+}
+
+// outbox returns a webhook for each event if webhooks are enabled.
+func (o options) outbox(paymentID string, ee ...dpp.Event) ([]dpp.OutboxMessage, error) {
+	if !o.webhooks {
+		return nil, nil
+	}
+	mm := make([]dpp.OutboxMessage, 0, len(ee))
+	for _, e := range ee {
+		id, err := newPaymentID()
+		if err != nil {
+			return nil, err
+		}
+		m, err := dpp.NewOutboxMessage(id, paymentID, e, time.Now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		mm = append(mm, m)
+	}
+	return mm, nil
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(context.Context, dpp.Event) error {
+	return nil
+}
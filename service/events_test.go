@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/events"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestPayment_Events(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	bus := events.NewBus()
+	defer bus.Close()
+	var got []dpp.Event
+	for _, et := range []dpp.EventType{dpp.EventPaymentReceived, dpp.EventPaymentRejected,
+		dpp.EventTransactionBroadcast, dpp.EventInvoiceExpired} {
+		bus.Subscribe(et, func(ctx context.Context, e dpp.Event) error {
+			got = append(got, e)
+			return nil
+		})
+	}
+	store := inmemory.NewStore()
+	now := time.Now().UTC()
+	_, err := store.InvoiceCreate(ctx, *dpp.NewInvoice("paid", now, time.Time{}))
+	is.NoErr(err)
+	_, err = store.InvoiceCreate(ctx, *dpp.NewInvoice("expired", now.Add(-time.Hour), now.Add(-time.Minute)))
+	is.NoErr(err)
+	reject := false
+	svc := service.NewPayment(&mocks.PaymentWriterMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			if reject {
+				return &dpp.PaymentACK{ID: args.PaymentID, Error: 1, Memo: "not enough"}, nil
+			}
+			return &dpp.PaymentACK{ID: args.PaymentID, TxID: "tx1"}, nil
+		},
+	}, store, service.WithEvents(bus))
+
+	reject = true
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "paid"}, validPayment())
+	is.NoErr(err)
+	reject = false
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "paid"}, validPayment())
+	is.NoErr(err)
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "expired"}, validPayment())
+	is.True(err != nil)
+
+	is.Equal(len(got), 5)
+	rejected := got[0].(dpp.PaymentRejected)
+	is.Equal(rejected.Reason, "not enough")
+	is.Equal(rejected.ACK.Error, 1)
+	received := got[1].(dpp.PaymentReceived)
+	is.Equal(received.PaymentID, "paid")
+	is.Equal(received.TxID, "tx1")
+	is.Equal(got[2].(dpp.TransactionBroadcast).TxID, "tx1")
+	is.Equal(got[3].(dpp.InvoiceExpired).PaymentID, "expired")
+	is.Equal(got[4].(dpp.PaymentRejected).PaymentID, "expired")
+	is.True(got[4].(dpp.PaymentRejected).ACK == nil)
+}
+
+func TestPaymentRequest_Events(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	bus := events.NewBus()
+	defer bus.Close()
+	served := make(chan dpp.Event, 1)
+	bus.Subscribe(dpp.EventPaymentRequestServed, func(ctx context.Context, e dpp.Event) error {
+		served <- e
+		return nil
+	}, events.WithAsync())
+	store := inmemory.NewStore()
+	_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{Network: "mainnet"})
+	is.NoErr(err)
+	svc := service.NewPaymentRequest(&service.PaymentRequestConfig{Network: "mainnet"}, nil, store, store, service.WithEvents(bus))
+	_, err = svc.PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	e := (<-served).(dpp.PaymentRequestServed)
+	is.Equal(e.PaymentID, "abc123")
+	is.Equal(e.PaymentRequest.Network, "mainnet")
+
+	// reads made by the payment host are not published as served.
+	_, err = svc.PaymentRequest(dpp.WithInternalRead(ctx), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	bus.Close()
+	is.Equal(len(served), 0)
+}
+
+func TestPayment_Webhooks(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	_, err := store.InvoiceCreate(ctx, *dpp.NewInvoice("abc123", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+	reject := true
+	svc := service.NewPayment(&mocks.PaymentWriterMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			if reject {
+				return &dpp.PaymentACK{ID: args.PaymentID, Error: 1, Memo: "not enough"}, nil
+			}
+			return store.PaymentCreate(ctx, args, req)
+		},
+	}, store, service.WithWebhooks())
+
+	// validation failures do not move the invoice so queue no webhook.
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{})
+	is.True(err != nil)
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, validPayment())
+	is.NoErr(err)
+	reject = false
+	_, err = svc.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, validPayment())
+	is.NoErr(err)
+
+	mm, err := store.OutboxMessages(ctx, dpp.OutboxArgs{})
+	is.NoErr(err)
+	is.Equal(len(mm), 3)
+	for i, et := range []dpp.EventType{dpp.EventPaymentRejected, dpp.EventPaymentReceived, dpp.EventTransactionBroadcast} {
+		is.Equal(mm[i].Type, et)
+		is.Equal(mm[i].PaymentID, "abc123")
+		is.Equal(mm[i].Status, dpp.WebhookStatusPending)
+		is.True(mm[i].ID != "")
+	}
+	var received dpp.PaymentReceived
+	is.NoErr(json.Unmarshal(mm[1].Payload, &received))
+	is.True(received.TxID != "")
+	is.Equal(received.ACK.TxID, received.TxID)
+}
+
+func TestPayment_WebhooksStoredWithPayment(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	// the invoice cannot move to paid but the payment has been stored so its webhooks are queued.
+	iw := &mocks.InvoiceWriterMock{
+		InvoiceUpdateFunc: func(ctx context.Context, args dpp.InvoiceArgs, req dpp.InvoiceUpdate) (*dpp.Invoice, error) {
+			if req.State == dpp.InvoiceStatePaid {
+				return nil, errors.New("store unavailable")
+			}
+			return &dpp.Invoice{ID: args.PaymentID, State: req.State}, nil
+		},
+	}
+	_, err := service.NewPayment(store, iw, service.WithWebhooks()).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, validPayment())
+	is.True(err != nil)
+	mm, err := store.OutboxMessages(ctx, dpp.OutboxArgs{})
+	is.NoErr(err)
+	is.Equal(len(mm), 2)
+	is.Equal(mm[0].Type, dpp.EventPaymentReceived)
+	is.Equal(mm[1].Type, dpp.EventTransactionBroadcast)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type invoiceExpiry struct {
+	irw dpp.InvoiceReaderWriter
+	options
+}
+
+// NewInvoiceExpiry will setup and return a new InvoiceExpiryService.
+//
+// With WithEvents it publishes InvoiceExpired, with WithWebhooks it is queued as a webhook
+// with the transition.
+func NewInvoiceExpiry(irw dpp.InvoiceReaderWriter, opts ...Opt) dpp.InvoiceExpiryService {
+	return &invoiceExpiry{irw: irw, options: newOptions(opts)}
+}
+
+// InvoicesExpire will read every invoice and expire those still created after their expiry time.
+// An invoice paid while the sweep is running is left as it is.
+func (i *invoiceExpiry) InvoicesExpire(ctx context.Context, at time.Time) ([]dpp.Invoice, error) {
+	invoices, err := i.irw.Invoices(ctx, dpp.InvoicesArgs{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read invoices")
+	}
+	expired := make([]dpp.Invoice, 0)
+	for _, inv := range invoices {
+		if inv.State != dpp.InvoiceStateCreated || !inv.Expired(at) {
+			continue
+		}
+		e := dpp.InvoiceExpired{
+			PaymentID: inv.ID,
+			ExpiresAt: inv.ExpiresAt,
+			Timestamp: time.Now().UTC(),
+		}
+		outbox, err := i.outbox(inv.ID, e)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := i.irw.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: inv.ID}, dpp.InvoiceUpdate{
+			State:     dpp.InvoiceStateExpired,
+			Timestamp: e.Timestamp,
+			Outbox:    outbox,
+		})
+		if err != nil {
+			var errT dpp.InvoiceTransitionError
+			if errors.As(err, &errT) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "failed to expire invoice %s", inv.ID)
+		}
+		i.publish(ctx, e)
+		expired = append(expired, *updated)
+	}
+	return expired, nil
+}
@@ -0,0 +1,64 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/events"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestInvoiceExpiry_InvoicesExpire(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	store := inmemory.NewStore()
+	for _, inv := range []*dpp.Invoice{
+		dpp.NewInvoice("expired", now.Add(-time.Hour*2), now.Add(-time.Hour)),
+		dpp.NewInvoice("open", now.Add(-time.Hour*2), now.Add(time.Hour)),
+		dpp.NewInvoice("noexpiry", now.Add(-time.Hour*2), time.Time{}),
+		dpp.NewInvoice("cancelled", now.Add(-time.Hour*2), now.Add(-time.Hour)),
+	} {
+		_, err := store.InvoiceCreate(ctx, *inv)
+		is.NoErr(err)
+	}
+	_, err := store.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: "cancelled"}, dpp.InvoiceUpdate{
+		State: dpp.InvoiceStateCancelled, Timestamp: now,
+	})
+	is.NoErr(err)
+
+	bus := events.NewBus()
+	defer bus.Close()
+	var published []string
+	bus.Subscribe(dpp.EventInvoiceExpired, func(ctx context.Context, e dpp.Event) error {
+		published = append(published, e.(dpp.InvoiceExpired).PaymentID)
+		return nil
+	})
+	svc := service.NewInvoiceExpiry(store, service.WithEvents(bus), service.WithWebhooks())
+
+	expired, err := svc.InvoicesExpire(ctx, now)
+	is.NoErr(err)
+	is.Equal(len(expired), 1)
+	is.Equal(expired[0].ID, "expired")
+	is.Equal(expired[0].State, dpp.InvoiceStateExpired)
+	is.Equal(published, []string{"expired"})
+	mm, err := store.OutboxMessages(ctx, dpp.OutboxArgs{})
+	is.NoErr(err)
+	is.Equal(len(mm), 1)
+	is.Equal(mm[0].PaymentID, "expired")
+
+	// invoices are only expired once.
+	expired, err = svc.InvoicesExpire(ctx, now)
+	is.NoErr(err)
+	is.Equal(len(expired), 0)
+
+	expired, err = svc.InvoicesExpire(ctx, now.Add(time.Hour*2))
+	is.NoErr(err)
+	is.Equal(len(expired), 1)
+	is.Equal(expired[0].ID, "open")
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type merchantDataTagger struct {
+	prr    dpp.PaymentRequestReader
+	secret []byte
+}
+
+// NewMerchantDataTagger will wrap a PaymentRequestReader and add an AuthTag to the
+// Beneficiary of each PaymentRequest read, authenticating its PaymentReference.
+// An error is returned if the secret is shorter than dpp.MerchantDataSecretMinLength.
+func NewMerchantDataTagger(prr dpp.PaymentRequestReader, secret []byte) (dpp.PaymentRequestReader, error) {
+	if err := dpp.ValidateMerchantDataSecret(secret); err != nil {
+		return nil, err
+	}
+	return &merchantDataTagger{prr: prr, secret: secret}, nil
+}
+
+// PaymentRequest will read the PaymentRequest and tag its Beneficiary.
+func (m *merchantDataTagger) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	pr, err := m.prr.PaymentRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if pr.Payee() == nil {
+		return pr, nil
+	}
+	// copy so a stored beneficiary is never modified.
+	beneficiary := *pr.Payee()
+	beneficiary.AuthTag = dpp.MerchantDataTag(m.secret, beneficiary)
+	pr.Beneficiary = &beneficiary
+	return pr, nil
+}
+
+type merchantDataVerifier struct {
+	svc    dpp.PaymentService
+	secret []byte
+}
+
+// NewMerchantDataVerifier will wrap a PaymentService and reject payments whose MerchantData
+// AuthTag is missing or invalid, or whose PaymentReference is not the invoice being paid.
+// An error is returned if the secret is shorter than dpp.MerchantDataSecretMinLength.
+func NewMerchantDataVerifier(svc dpp.PaymentService, secret []byte) (dpp.PaymentService, error) {
+	if err := dpp.ValidateMerchantDataSecret(secret); err != nil {
+		return nil, err
+	}
+	return &merchantDataVerifier{svc: svc, secret: secret}, nil
+}
+
+// PaymentCreate will check the MerchantData AuthTag before passing the payment to the wrapped service.
+func (m *merchantDataVerifier) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validator.New().
+		Validate("merchantData.authTag", func() error {
+			if !dpp.VerifyMerchantDataTag(m.secret, req.MerchantData) {
+				return errors.New("authTag is missing or invalid")
+			}
+			return nil
+		}).
+		Validate("merchantData.paymentReference", func() error {
+			if req.MerchantData.Reference() != args.PaymentID {
+				return fmt.Errorf("paymentReference does not match payment %s", args.PaymentID)
+			}
+			return nil
+		}).Err(); err != nil {
+		return nil, err
+	}
+	return m.svc.PaymentCreate(ctx, args, req)
+}
@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestMerchantData_AuthTag(t *testing.T) {
+	ctx := context.Background()
+	secret := []byte("payment host secret of 32 bytes!")
+	store := inmemory.NewStore()
+	for _, id := range []string{"invoice1", "invoice2"} {
+		if _, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: id}, dpp.PaymentRequest{
+			Beneficiary: &dpp.Beneficiary{Name: "merchant", PaymentReference: id},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tagger, err := service.NewMerchantDataTagger(store, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued := func(id string) dpp.Beneficiary {
+		pr, err := tagger.PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *pr.Beneficiary
+	}
+	forged := issued("invoice2")
+	forged.PaymentReference = "invoice1"
+	untagged := issued("invoice1")
+	untagged.AuthTag = ""
+	otherSecret := dpp.Beneficiary{PaymentReference: "invoice1"}
+	otherSecret.AuthTag = dpp.MerchantDataTag([]byte("another secret"), otherSecret)
+
+	tests := map[string]struct {
+		paymentID    string
+		merchantData dpp.Beneficiary
+		expErr       string
+	}{
+		"issued tag should be accepted": {
+			paymentID:    "invoice1",
+			merchantData: issued("invoice1"),
+		}, "missing tag should be rejected": {
+			paymentID:    "invoice1",
+			merchantData: untagged,
+			expErr:       "[merchantData.authTag: authTag is missing or invalid]",
+		}, "reference changed to another invoice should be rejected": {
+			paymentID:    "invoice1",
+			merchantData: forged,
+			expErr:       "[merchantData.authTag: authTag is missing or invalid]",
+		}, "valid merchant data for another invoice should be rejected": {
+			paymentID:    "invoice1",
+			merchantData: issued("invoice2"),
+			expErr:       "[merchantData.paymentReference: paymentReference does not match payment invoice1]",
+		}, "tag from another secret should be rejected": {
+			paymentID:    "invoice1",
+			merchantData: otherSecret,
+			expErr:       "[merchantData.authTag: authTag is missing or invalid]",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			svc := &mocks.PaymentServiceMock{
+				PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+					return &dpp.PaymentACK{ID: args.PaymentID}, nil
+				},
+			}
+			payment, _ := paymentFromTx(t, []uint32{0}, 1000)
+			payment.MerchantData = test.merchantData
+			verifier, err := service.NewMerchantDataVerifier(svc, secret)
+			is.NoErr(err)
+			_, err = verifier.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: test.paymentID}, payment)
+			if test.expErr != "" {
+				is.True(err != nil)
+				is.Equal(err.Error(), test.expErr)
+				is.Equal(len(svc.PaymentCreateCalls()), 0)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(len(svc.PaymentCreateCalls()), 1)
+		})
+	}
+
+	// the stored PaymentRequest is not modified.
+	pr, err := store.PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: "invoice1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	is.New(t).Equal(pr.Beneficiary.AuthTag, "")
+}
+
+func TestMerchantData_ShortSecret(t *testing.T) {
+	is := is.New(t)
+	for _, secret := range [][]byte{nil, []byte("too short")} {
+		_, err := service.NewMerchantDataTagger(inmemory.NewStore(), secret)
+		is.Equal(err.Error(), "merchant data secret must be at least 32 bytes")
+		_, err = service.NewMerchantDataVerifier(&mocks.PaymentServiceMock{}, secret)
+		is.Equal(err.Error(), "merchant data secret must be at least 32 bytes")
+	}
+}
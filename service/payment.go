@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type payment struct {
+	pw dpp.PaymentWriter
+	iw dpp.InvoiceWriter
+	options
+}
+
+// NewPayment will setup and return a new PaymentService which stores payments
+// using the PaymentWriter and moves the invoice through its lifecycle as the payment progresses.
+//
+// With WithEvents it publishes PaymentReceived, PaymentRejected, TransactionBroadcast and InvoiceExpired,
+// with WithWebhooks the same events are queued as webhooks. PaymentReceived and TransactionBroadcast
+// are queued by the PaymentWriter with the payment using PaymentCreateArgs.Outbox, the others are
+// queued when they move the invoice.
+func NewPayment(pw dpp.PaymentWriter, iw dpp.InvoiceWriter, opts ...Opt) dpp.PaymentService {
+	return &payment{pw: pw, iw: iw, options: newOptions(opts)}
+}
+
+// PaymentCreate will validate the payment, mark the invoice as pending and then
+// pass the payment to the PaymentWriter.
+//
+// If the payment is rejected the invoice is returned to created so it can be paid again,
+// if it is accepted the invoice is marked as paid and broadcast.
+func (p *payment) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		p.publish(ctx, p.rejected(args, err.Error(), nil))
+		return nil, err
+	}
+	invArgs := dpp.InvoiceArgs{PaymentID: args.PaymentID}
+	if _, err := p.iw.InvoiceUpdate(ctx, invArgs, dpp.InvoiceUpdate{
+		State:     dpp.InvoiceStatePending,
+		Timestamp: time.Now().UTC(),
+	}); err != nil {
+		var errExp dpp.InvoiceExpiredError
+		if errors.As(err, &errExp) {
+			if err := p.transition(ctx, invArgs, dpp.InvoiceStateExpired, "", dpp.InvoiceExpired{
+				PaymentID: args.PaymentID,
+				ExpiresAt: errExp.ExpiresAt,
+				Timestamp: time.Now().UTC(),
+			}); err != nil {
+				return nil, errors.Wrapf(err, "failed to expire invoice %s", args.PaymentID)
+			}
+		}
+		p.publish(ctx, p.rejected(args, err.Error(), nil))
+		return nil, err
+	}
+	// the accepted events are built when the writer stores their webhooks with the payment.
+	var received dpp.PaymentReceived
+	var broadcast dpp.TransactionBroadcast
+	accepted := func(ack dpp.PaymentACK) {
+		received = dpp.PaymentReceived{
+			PaymentID: args.PaymentID,
+			TxID:      ack.TxID,
+			Payment:   req,
+			ACK:       ack,
+			Timestamp: time.Now().UTC(),
+		}
+		broadcast = dpp.TransactionBroadcast{
+			PaymentID: args.PaymentID,
+			TxID:      ack.TxID,
+			Timestamp: received.Timestamp,
+		}
+	}
+	if p.webhooks {
+		paymentID := args.PaymentID
+		args.Outbox = func(ack dpp.PaymentACK) ([]dpp.OutboxMessage, error) {
+			accepted(ack)
+			return p.outbox(paymentID, received, broadcast)
+		}
+	}
+	ack, err := p.pw.PaymentCreate(ctx, args, req)
+	if err == nil && ack == nil {
+		err = errors.New("payment writer returned no payment ack")
+	}
+	if err != nil || ack.Error > 0 {
+		e := p.rejected(args, "", ack)
+		if err != nil {
+			e = p.rejected(args, err.Error(), nil)
+		}
+		if uErr := p.transition(ctx, invArgs, dpp.InvoiceStateCreated, "", e); uErr != nil {
+			return nil, errors.Wrapf(uErr, "failed to reset invoice %s after rejected payment", args.PaymentID)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to store payment")
+		}
+		return ack, nil
+	}
+	if received.PaymentID == "" {
+		accepted(*ack)
+	}
+	if err := p.move(ctx, invArgs, dpp.InvoiceStatePaid, ack.TxID, nil, received); err != nil {
+		return nil, errors.Wrapf(err, "failed to mark invoice %s as %s", args.PaymentID, dpp.InvoiceStatePaid)
+	}
+	if err := p.move(ctx, invArgs, dpp.InvoiceStateBroadcast, ack.TxID, nil, broadcast); err != nil {
+		return nil, errors.Wrapf(err, "failed to mark invoice %s as %s", args.PaymentID, dpp.InvoiceStateBroadcast)
+	}
+	return ack, nil
+}
+
+// transition will move the invoice to the state, queueing a webhook for the event with it,
+// and publish the event once it has moved.
+func (p *payment) transition(ctx context.Context, args dpp.InvoiceArgs, state dpp.InvoiceState, txID string, e dpp.Event) error {
+	outbox, err := p.outbox(args.PaymentID, e)
+	if err != nil {
+		return err
+	}
+	return p.move(ctx, args, state, txID, outbox, e)
+}
+
+// move will move the invoice to the state along with the outbox messages and publish the event once it has moved.
+func (p *payment) move(ctx context.Context, args dpp.InvoiceArgs, state dpp.InvoiceState, txID string, outbox []dpp.OutboxMessage, e dpp.Event) error {
+	if _, err := p.iw.InvoiceUpdate(ctx, args, dpp.InvoiceUpdate{
+		State:     state,
+		TxID:      txID,
+		Timestamp: time.Now().UTC(),
+		Outbox:    outbox,
+	}); err != nil {
+		return err
+	}
+	p.publish(ctx, e)
+	return nil
+}
+
+func (p *payment) rejected(args dpp.PaymentCreateArgs, reason string, ack *dpp.PaymentACK) dpp.PaymentRejected {
+	if ack != nil {
+		reason = ack.Memo
+	}
+	return dpp.PaymentRejected{
+		PaymentID: args.PaymentID,
+		Reason:    reason,
+		ACK:       ack,
+		Timestamp: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type paymentGuard struct {
+	svc dpp.PaymentService
+	idx dpp.PaymentIndexReaderWriter
+}
+
+// NewPaymentGuard will wrap a PaymentService and reject duplicate and double payments.
+//
+// An exact retry of an accepted payment returns the original PaymentACK, the same tx
+// submitted against another invoice or a different tx spending outpoints already
+// spent by a received payment is rejected with a PaymentConflictError.
+//
+// A payment still in progress after dpp.PaymentIndexInProgressTimeout, ie the ACK could not be
+// stored, is released so it can be retried.
+func NewPaymentGuard(svc dpp.PaymentService, idx dpp.PaymentIndexReaderWriter) dpp.PaymentService {
+	return &paymentGuard{svc: svc, idx: idx}
+}
+
+// PaymentCreate will check the payment against the index before passing it to the wrapped service.
+func (p *paymentGuard) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.RawTx == nil {
+		return nil, validator.ErrValidation{"rawTx": []string{"rawTx is required"}}
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid rawTx supplied")
+	}
+	txID := tx.TxID()
+	existing, err := p.idx.PaymentIndex(ctx, txID)
+	if err != nil && !errors.Is(err, dpp.ErrPaymentIndexNotFound) {
+		return nil, errors.Wrapf(err, "failed to read payment index for %s", txID)
+	}
+	if existing != nil && existing.PaymentID == args.PaymentID && existing.Abandoned(time.Now().UTC()) {
+		if err := p.idx.PaymentIndexDelete(ctx, txID); err != nil {
+			return nil, errors.Wrapf(err, "failed to release abandoned payment index for %s", txID)
+		}
+		existing = nil
+	}
+	if existing != nil {
+		switch {
+		case existing.PaymentID != args.PaymentID:
+			return nil, dpp.PaymentConflictError{
+				Reason:            dpp.PaymentConflictTxReused,
+				PaymentID:         args.PaymentID,
+				TxID:              txID,
+				ConflictPaymentID: existing.PaymentID,
+				ConflictTxID:      existing.TxID,
+			}
+		case existing.ACK == nil:
+			return nil, dpp.PaymentConflictError{
+				Reason:            dpp.PaymentConflictInProgress,
+				PaymentID:         args.PaymentID,
+				TxID:              txID,
+				ConflictPaymentID: existing.PaymentID,
+				ConflictTxID:      existing.TxID,
+			}
+		}
+		return existing.ACK, nil
+	}
+	outpoints := make([]string, 0, len(tx.Inputs))
+	for _, in := range tx.Inputs {
+		outpoints = append(outpoints, fmt.Sprintf("%s:%d", in.PreviousTxIDStr(), in.PreviousTxOutIndex))
+	}
+	if err := p.idx.PaymentIndexCreate(ctx, dpp.PaymentIndexEntry{
+		PaymentID: args.PaymentID,
+		TxID:      txID,
+		Outpoints: outpoints,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return nil, err
+	}
+	ack, err := p.svc.PaymentCreate(ctx, args, req)
+	if err != nil || ack == nil || ack.Error > 0 {
+		if dErr := p.idx.PaymentIndexDelete(ctx, txID); dErr != nil {
+			return nil, errors.Wrapf(dErr, "failed to release payment index for %s", txID)
+		}
+		return ack, err
+	}
+	if err := p.idx.PaymentIndexUpdate(ctx, txID, *ack); err != nil {
+		return nil, errors.Wrapf(err, "failed to update payment index for %s", txID)
+	}
+	return ack, nil
+}
@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func paymentFromTx(t *testing.T, vouts []uint32, satoshis uint64) (dpp.Payment, string) {
+	tx := bt.NewTx()
+	for _, v := range vouts {
+		if err := tx.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", v,
+			"76a91455b61be43392125d127f1780fb038437cd67ef9c88ac", 10000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", satoshis); err != nil {
+		t.Fatal(err)
+	}
+	rawTx := tx.String()
+	return dpp.Payment{
+		RawTx: &rawTx,
+		MerchantData: dpp.Merchant{
+			ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+		},
+	}, tx.TxID()
+}
+
+func TestPaymentGuard_PaymentCreate(t *testing.T) {
+	ctx := context.Background()
+	first, firstTxID := paymentFromTx(t, []uint32{0, 1}, 1000)
+	doubleSpend, doubleSpendTxID := paymentFromTx(t, []uint32{1, 2}, 900)
+	unrelated, _ := paymentFromTx(t, []uint32{3}, 1000)
+
+	tests := map[string]struct {
+		paymentID string
+		req       dpp.Payment
+		expErr    error
+		expCalls  int
+	}{
+		"exact retry should return the original ack": {
+			paymentID: "invoice1",
+			req:       first,
+			expCalls:  1,
+		}, "same tx for another invoice should be rejected": {
+			paymentID: "invoice2",
+			req:       first,
+			expErr: dpp.PaymentConflictError{
+				Reason:            dpp.PaymentConflictTxReused,
+				PaymentID:         "invoice2",
+				TxID:              firstTxID,
+				ConflictPaymentID: "invoice1",
+				ConflictTxID:      firstTxID,
+			},
+			expCalls: 1,
+		}, "different tx spending the same outpoint should be rejected": {
+			paymentID: "invoice2",
+			req:       doubleSpend,
+			expErr: dpp.PaymentConflictError{
+				Reason:            dpp.PaymentConflictOutpointSpent,
+				PaymentID:         "invoice2",
+				TxID:              doubleSpendTxID,
+				ConflictPaymentID: "invoice1",
+				ConflictTxID:      firstTxID,
+				Outpoint:          "3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5:1",
+			},
+			expCalls: 1,
+		}, "unrelated tx should be accepted": {
+			paymentID: "invoice2",
+			req:       unrelated,
+			expCalls:  2,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			svc := &mocks.PaymentServiceMock{
+				PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+					return &dpp.PaymentACK{ID: args.PaymentID, Memo: args.PaymentID}, nil
+				},
+			}
+			guard := service.NewPaymentGuard(svc, inmemory.NewStore())
+			ack, err := guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, first)
+			is.NoErr(err)
+			is.Equal(ack.Memo, "invoice1")
+
+			ack, err = guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: test.paymentID}, test.req)
+			is.Equal(len(svc.PaymentCreateCalls()), test.expCalls)
+			if test.expErr != nil {
+				var errConflict dpp.PaymentConflictError
+				is.True(errors.As(err, &errConflict))
+				is.Equal(test.expErr, errConflict)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(ack.ID, test.paymentID)
+		})
+	}
+}
+
+func TestPaymentGuard_RejectedPaymentReleasesIndex(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	req, _ := paymentFromTx(t, []uint32{0}, 1000)
+	reject := true
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			if reject {
+				return &dpp.PaymentACK{ID: args.PaymentID, Error: 1}, nil
+			}
+			return &dpp.PaymentACK{ID: args.PaymentID}, nil
+		},
+	}
+	guard := service.NewPaymentGuard(svc, inmemory.NewStore())
+	ack, err := guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.NoErr(err)
+	is.Equal(ack.Error, 1)
+
+	reject = false
+	ack, err = guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.NoErr(err)
+	is.Equal(ack.Error, 0)
+	is.Equal(len(svc.PaymentCreateCalls()), 2)
+}
+
+func TestPaymentGuard_NoACKReleasesIndex(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	req, _ := paymentFromTx(t, []uint32{0}, 1000)
+	var ack *dpp.PaymentACK
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			return ack, nil
+		},
+	}
+	guard := service.NewPaymentGuard(svc, inmemory.NewStore())
+	got, err := guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.NoErr(err)
+	is.True(got == nil)
+
+	ack = &dpp.PaymentACK{ID: "invoice1"}
+	got, err = guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.NoErr(err)
+	is.Equal(got.ID, "invoice1")
+}
+
+// failingIndexUpdate fails to store the ACK of an accepted payment.
+type failingIndexUpdate struct {
+	*inmemory.Store
+}
+
+func (f failingIndexUpdate) PaymentIndexUpdate(ctx context.Context, txID string, ack dpp.PaymentACK) error {
+	return errors.New("index unavailable")
+}
+
+func TestPaymentGuard_AbandonedIndexExpires(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	req, txID := paymentFromTx(t, []uint32{0}, 1000)
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			return &dpp.PaymentACK{ID: args.PaymentID}, nil
+		},
+	}
+	store := inmemory.NewStore()
+	_, err := service.NewPaymentGuard(svc, failingIndexUpdate{store}).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.True(err != nil)
+
+	// the entry is in progress until it times out.
+	guard := service.NewPaymentGuard(svc, store)
+	_, err = guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	var errConflict dpp.PaymentConflictError
+	is.True(errors.As(err, &errConflict))
+	is.Equal(errConflict.Reason, dpp.PaymentConflictInProgress)
+	is.Equal(len(svc.PaymentCreateCalls()), 1)
+
+	entry, err := store.PaymentIndex(ctx, txID)
+	is.NoErr(err)
+	is.NoErr(store.PaymentIndexDelete(ctx, txID))
+	entry.CreatedAt = entry.CreatedAt.Add(-dpp.PaymentIndexInProgressTimeout - time.Second)
+	is.NoErr(store.PaymentIndexCreate(ctx, *entry))
+
+	// an abandoned entry for another invoice still conflicts.
+	_, err = guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice2"}, req)
+	is.True(errors.As(err, &errConflict))
+	is.Equal(errConflict.Reason, dpp.PaymentConflictTxReused)
+
+	ack, err := guard.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, req)
+	is.NoErr(err)
+	is.Equal(ack.ID, "invoice1")
+	is.Equal(len(svc.PaymentCreateCalls()), 2)
+	entry, err = store.PaymentIndex(ctx, txID)
+	is.NoErr(err)
+	is.True(entry.ACK != nil)
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PaymentRequestConfig contains the settings used when creating PaymentRequests.
+type PaymentRequestConfig struct {
+	// Network is the bitcoin network payments should be made on, ie mainnet.
+	Network string
+	// PaymentURL is the base url payments are sent to, the paymentID is appended to it.
+	PaymentURL string
+	// FeeRate is the fee quote sent to wallets, if nil the go-bt defaults are used.
+	FeeRate *bt.FeeQuote
+}
+
+type paymentRequest struct {
+	cfg *PaymentRequestConfig
+	dc  dpp.DestinationsCreator
+	prw dpp.PaymentRequestReaderWriter
+	iw  dpp.InvoiceWriter
+	options
+}
+
+// NewPaymentRequest will setup and return a new PaymentRequestReaderCreator which can
+// read existing PaymentRequests and create new ones along with their invoice.
+//
+// With WithEvents it publishes PaymentRequestServed, except for reads marked with dpp.WithInternalRead.
+func NewPaymentRequest(cfg *PaymentRequestConfig, dc dpp.DestinationsCreator, prw dpp.PaymentRequestReaderWriter, iw dpp.InvoiceWriter, opts ...Opt) dpp.PaymentRequestReaderCreator {
+	return &paymentRequest{cfg: cfg, dc: dc, prw: prw, iw: iw, options: newOptions(opts)}
+}
+
+// PaymentRequest will return a stored PaymentRequest.
+func (p *paymentRequest) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	pr, err := p.prw.PaymentRequest(ctx, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read payment request %s", args.PaymentID)
+	}
+	if !dpp.InternalRead(ctx) {
+		p.publish(ctx, dpp.PaymentRequestServed{
+			PaymentID:      args.PaymentID,
+			PaymentRequest: *pr,
+			Timestamp:      time.Now().UTC(),
+		})
+	}
+	return pr, nil
+}
+
+// PaymentRequestCreate will generate a new paymentID and create destinations for the requested
+// amounts, unless explicit outputs are supplied. The destinations are also offered as a DPP 1.0
+// HybridPaymentMode. The PaymentRequest is stored and an invoice created for it in the created state.
+func (p *paymentRequest) PaymentRequestCreate(ctx context.Context, req dpp.PaymentRequestCreate) (*dpp.PaymentRequest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	paymentID, err := newPaymentID()
+	if err != nil {
+		return nil, err
+	}
+	args := dpp.PaymentRequestArgs{PaymentID: paymentID}
+	outputs := append([]dpp.Output{}, req.Outputs...)
+	for i := range outputs {
+		if err := outputs[i].DecodeBIP276(p.cfg.Network); err != nil {
+			return nil, validator.ErrValidation{fmt.Sprintf("outputs[%d].bip276", i): []string{err.Error()}}
+		}
+	}
+	if len(outputs) == 0 {
+		dests, err := p.dc.DestinationsCreate(ctx, dpp.DestinationsArgs{PaymentID: paymentID}, dpp.DestinationsCreate{
+			Amounts: req.Amounts,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create destinations for payment %s", paymentID)
+		}
+		outputs = dests.Outputs
+	}
+	fees := p.cfg.FeeRate
+	if fees == nil {
+		fees = bt.NewFeeQuote()
+	}
+	dests := dpp.PaymentDestinations{Outputs: outputs, Data: req.Data}
+	hybrid, err := dpp.NewHybridPaymentMode(dests, fees, req.AncestryRequired)
+	if err != nil {
+		return nil, err
+	}
+	hybridJSON, err := json.Marshal(hybrid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode hybrid payment mode")
+	}
+	beneficiary := req.Payee()
+	beneficiary.PaymentReference = paymentID
+
+	pr, err := p.prw.PaymentRequestCreate(ctx, args, dpp.PaymentRequest{
+		Network:             p.cfg.Network,
+		AncestryRequired:    req.AncestryRequired,
+		Destinations:        dests,
+		CreationTimestamp:   time.Now().UTC(),
+		ExpirationTimestamp: req.ExpirationTimestamp,
+		PaymentURL:          fmt.Sprintf("%s/%s", strings.TrimSuffix(p.cfg.PaymentURL, "/"), paymentID),
+		Memo:                req.Memo,
+		Beneficiary:         &beneficiary,
+		FeeRate:             fees,
+		Modes:               map[string]json.RawMessage{dpp.PaymentModeHybrid: hybridJSON},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to store payment request %s", paymentID)
+	}
+	if _, err := p.iw.InvoiceCreate(ctx, *dpp.NewInvoice(paymentID, pr.CreationTimestamp, pr.ExpirationTimestamp)); err != nil {
+		return nil, errors.Wrapf(err, "failed to create invoice %s", paymentID)
+	}
+	return pr, nil
+}
+
+// newPaymentID returns a random hex encoded identifier for a PaymentRequest.
+func newPaymentID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate paymentID")
+	}
+	return hex.EncodeToString(b), nil
+}
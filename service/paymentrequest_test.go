@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestPaymentRequest_PaymentRequestCreate(t *testing.T) {
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testnet := bscript.EncodeBIP276(bscript.BIP276{
+		Prefix:  bscript.PrefixScript,
+		Network: bscript.NetworkTestnet,
+		Version: bscript.CurrentVersion,
+		Data:    *script,
+	})
+	mainnet := bscript.EncodeBIP276(bscript.BIP276{
+		Prefix:  bscript.PrefixScript,
+		Network: bscript.NetworkMainnet,
+		Version: bscript.CurrentVersion,
+		Data:    *script,
+	})
+	tests := map[string]struct {
+		req        dpp.PaymentRequestCreate
+		expOutputs []dpp.Output
+		expErr     string
+	}{
+		"amounts should have destinations created": {
+			req: dpp.PaymentRequestCreate{
+				Amounts: []uint64{1000, 2000},
+				Memo:    "invoice 123",
+			},
+			expOutputs: []dpp.Output{
+				{Amount: 1000, LockingScript: script},
+				{Amount: 2000, LockingScript: script},
+			},
+		}, "explicit outputs should be used as is": {
+			req: dpp.PaymentRequestCreate{
+				Outputs: []dpp.Output{{Amount: 500, LockingScript: script, Description: "tip"}},
+			},
+			expOutputs: []dpp.Output{{Amount: 500, LockingScript: script, Description: "tip"}},
+		}, "bip276 outputs should be decoded": {
+			req: dpp.PaymentRequestCreate{
+				Outputs: []dpp.Output{{Amount: 500, BIP276: testnet}},
+			},
+			expOutputs: []dpp.Output{{Amount: 500, LockingScript: script, BIP276: testnet}},
+		}, "bip276 outputs for another network should error": {
+			req: dpp.PaymentRequestCreate{
+				Outputs: []dpp.Output{{Amount: 500, BIP276: mainnet}},
+			},
+			expErr: "[outputs[0].bip276: bip276 network 1 does not match testnet]",
+		}, "amounts and outputs together should error": {
+			req: dpp.PaymentRequestCreate{
+				Amounts: []uint64{1000},
+				Outputs: []dpp.Output{{Amount: 500, LockingScript: script}},
+			},
+			expErr: "[amounts/outputs: only one of amounts or outputs should be supplied]",
+		}, "expiry in the past should error": {
+			req: dpp.PaymentRequestCreate{
+				Amounts:             []uint64{1000},
+				ExpirationTimestamp: time.Now().Add(-time.Hour),
+			},
+			expErr: "[expirationTimestamp: ",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			store := inmemory.NewStore()
+			dc := &mocks.DestinationsCreatorMock{
+				DestinationsCreateFunc: func(ctx context.Context, args dpp.DestinationsArgs, req dpp.DestinationsCreate) (*dpp.PaymentDestinations, error) {
+					outs := make([]dpp.Output, 0, len(req.Amounts))
+					for _, a := range req.Amounts {
+						outs = append(outs, dpp.Output{Amount: a, LockingScript: script})
+					}
+					return &dpp.PaymentDestinations{Outputs: outs}, nil
+				},
+			}
+			svc := service.NewPaymentRequest(&service.PaymentRequestConfig{
+				Network:    "testnet",
+				PaymentURL: "http://dpp/api/v1/payment/",
+			}, dc, store, store)
+			pr, err := svc.PaymentRequestCreate(context.Background(), test.req)
+			if test.expErr != "" {
+				is.True(err != nil)
+				is.True(len(err.Error()) >= len(test.expErr))
+				is.Equal(test.expErr, err.Error()[:len(test.expErr)])
+				return
+			}
+			is.NoErr(err)
+			paymentID := pr.Beneficiary.PaymentReference
+			is.Equal(len(paymentID), 32)
+			is.Equal(pr.PaymentURL, "http://dpp/api/v1/payment/"+paymentID)
+			is.Equal(pr.Network, "testnet")
+			is.Equal(pr.Destinations.Outputs, test.expOutputs)
+
+			stored, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: paymentID})
+			is.NoErr(err)
+			is.Equal(stored.PaymentURL, pr.PaymentURL)
+
+			inv, err := store.Invoice(context.Background(), dpp.InvoiceArgs{PaymentID: paymentID})
+			is.NoErr(err)
+			is.Equal(inv.State, dpp.InvoiceStateCreated)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type payments struct {
+	pr dpp.PaymentReader
+}
+
+// NewPayments will setup and return a new service used to browse stored payments.
+func NewPayments(pr dpp.PaymentReader) dpp.PaymentReader {
+	return &payments{pr: pr}
+}
+
+// Payments will validate the filters, apply the default page size and return a page of payments.
+func (p *payments) Payments(ctx context.Context, args dpp.PaymentsArgs) (*dpp.PaymentsPage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if args.Limit == 0 {
+		args.Limit = dpp.PaymentsDefaultLimit
+	}
+	page, err := p.pr.Payments(ctx, args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read payments")
+	}
+	return page, nil
+}
@@ -0,0 +1,83 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/service"
+)
+
+type paymentReaderFunc func(ctx context.Context, args dpp.PaymentsArgs) (*dpp.PaymentsPage, error)
+
+func (p paymentReaderFunc) Payments(ctx context.Context, args dpp.PaymentsArgs) (*dpp.PaymentsPage, error) {
+	return p(ctx, args)
+}
+
+func TestPayments_Payments(t *testing.T) {
+	now := time.Date(2021, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		args     dpp.PaymentsArgs
+		readErr  error
+		expLimit int
+		expErr   error
+	}{
+		"no limit should use the default": {
+			args:     dpp.PaymentsArgs{},
+			expLimit: dpp.PaymentsDefaultLimit,
+		}, "limit and cursor should be passed to the reader": {
+			args:     dpp.PaymentsArgs{Limit: 10, Cursor: "abc"},
+			expLimit: 10,
+		}, "limit above the max should error": {
+			args:   dpp.PaymentsArgs{Limit: dpp.PaymentsMaxLimit + 1},
+			expErr: errors.New("[limit: value 501 must be between 0 and 500]"),
+		}, "unknown state should error": {
+			args:   dpp.PaymentsArgs{State: "lost"},
+			expErr: errors.New("[state: value not found in allowed values]"),
+		}, "to before from should error": {
+			args:   dpp.PaymentsArgs{From: now, To: now.Add(-time.Hour)},
+			expErr: errors.New("[to: the date provided 2021-01-01 11:00:00 +0000 UTC, must be after 2021-01-01 12:00:00 +0000 UTC]"),
+		}, "max amount below min amount should error": {
+			args:   dpp.PaymentsArgs{MinAmount: 2000, MaxAmount: 1000},
+			expErr: errors.New("[maxAmount: value 1000 is smaller than minimum 2000]"),
+		}, "reader error should be wrapped": {
+			args:     dpp.PaymentsArgs{Limit: 5},
+			readErr:  errors.New("boom"),
+			expLimit: 5,
+			expErr:   errors.New("failed to read payments: boom"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			var got *dpp.PaymentsArgs
+			svc := service.NewPayments(paymentReaderFunc(func(ctx context.Context, args dpp.PaymentsArgs) (*dpp.PaymentsPage, error) {
+				got = &args
+				if test.readErr != nil {
+					return nil, test.readErr
+				}
+				return &dpp.PaymentsPage{Payments: []dpp.PaymentRecord{{PaymentID: "abc123"}}, NextCursor: "next"}, nil
+			}))
+			page, err := svc.Payments(context.Background(), test.args)
+			if test.expLimit > 0 {
+				is.True(got != nil)
+				is.Equal(got.Limit, test.expLimit)
+				is.Equal(got.Cursor, test.args.Cursor)
+			} else {
+				is.True(got == nil)
+			}
+			if test.expErr != nil {
+				is.True(err != nil)
+				is.Equal(test.expErr.Error(), err.Error())
+				return
+			}
+			is.NoErr(err)
+			is.Equal(page.NextCursor, "next")
+			is.Equal(len(page.Payments), 1)
+		})
+	}
+}
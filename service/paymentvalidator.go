@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type paymentValidator struct {
+	svc   dpp.PaymentService
+	prr   dpp.PaymentRequestReader
+	ur    dpp.UTXOReader
+	modes dpp.PaymentModes
+}
+
+// NewPaymentValidator will wrap a PaymentService and reject payments that do not satisfy the
+// stored PaymentRequest, ie missing outputs or data outputs, an invalid mode and option, or
+// too few fees at the request FeeRate. The inputs are read from the payment ancestry, when
+// supplied, or from ur to calculate the fees. If the request has AncestryRequired every input
+// must be in the ancestry.
+//
+// Payments made using a mode have their rawTx set from the validated mode transaction, so the validator
+// should wrap any other decorators. Only modes registered in modes are accepted.
+func NewPaymentValidator(svc dpp.PaymentService, prr dpp.PaymentRequestReader, ur dpp.UTXOReader, modes dpp.PaymentModes) dpp.PaymentService {
+	return &paymentValidator{svc: svc, prr: prr, ur: ur, modes: modes}
+}
+
+// PaymentCreate will validate the payment against the PaymentRequest before passing it to the wrapped service.
+func (p *paymentValidator) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	pr, err := p.prr.PaymentRequest(dpp.WithInternalRead(ctx), dpp.PaymentRequestArgs{PaymentID: args.PaymentID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read payment request %s", args.PaymentID)
+	}
+	txs, err := p.modes.Validate(*pr, req)
+	if err != nil {
+		return nil, err
+	}
+	ur, err := p.utxoReader(*pr, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if err := p.validateFees(ctx, *pr, ur, tx); err != nil {
+			return nil, err
+		}
+	}
+	if req.ModeID != "" {
+		if len(txs) != 1 {
+			return nil, validator.ErrValidation{"mode.transactions": []string{"only single transaction payments are supported"}}
+		}
+		// a payment cannot have both a rawTx and a mode, the wrapped services only see the rawTx.
+		rawTx := txs[0].String()
+		req.RawTx = &rawTx
+		req.ModeID = ""
+		req.Mode = nil
+	}
+	return p.svc.PaymentCreate(ctx, args, req)
+}
+
+// utxoReader returns the reader used to read input values, the payment ancestry if supplied.
+func (p *paymentValidator) utxoReader(pr dpp.PaymentRequest, req dpp.Payment) (dpp.UTXOReader, error) {
+	if req.Ancestry == nil || *req.Ancestry == "" {
+		if pr.AncestryRequired {
+			return nil, validator.ErrValidation{"ancestry": []string{"ancestry is required by the payment request"}}
+		}
+		return p.ur, nil
+	}
+	ancestry, err := dpp.NewAncestryFromHex(*req.Ancestry)
+	if err != nil {
+		return nil, validator.ErrValidation{"ancestry": []string{err.Error()}}
+	}
+	if pr.AncestryRequired {
+		return ancestry, nil
+	}
+	return ancestryUTXOs{ancestry: ancestry, ur: p.ur}, nil
+}
+
+// ancestryUTXOs reads outputs from the ancestry, falling back to the reader for inputs it does not contain.
+type ancestryUTXOs struct {
+	ancestry dpp.Ancestry
+	ur       dpp.UTXOReader
+}
+
+func (a ancestryUTXOs) UTXO(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+	utxo, err := a.ancestry.UTXO(ctx, txID, vout)
+	if errors.Is(err, dpp.ErrUTXONotFound) {
+		return a.ur.UTXO(ctx, txID, vout)
+	}
+	return utxo, err
+}
+
+// validateFees will set the value of each input from the utxo it spends and check the tx pays enough fees.
+func (p *paymentValidator) validateFees(ctx context.Context, pr dpp.PaymentRequest, ur dpp.UTXOReader, tx *bt.Tx) error {
+	for i, in := range tx.Inputs {
+		utxo, err := ur.UTXO(ctx, in.PreviousTxIDStr(), in.PreviousTxOutIndex)
+		if err != nil && !errors.Is(err, dpp.ErrUTXONotFound) {
+			return errors.Wrapf(err, "failed to read utxo %s:%d", in.PreviousTxIDStr(), in.PreviousTxOutIndex)
+		}
+		if utxo == nil {
+			return validator.ErrValidation{fmt.Sprintf("inputs[%d]", i): []string{
+				fmt.Sprintf("spends unknown output %s:%d", in.PreviousTxIDStr(), in.PreviousTxOutIndex),
+			}}
+		}
+		in.PreviousTxSatoshis = utxo.Satoshis
+		in.PreviousTxScript = utxo.LockingScript
+	}
+	return pr.ValidateFees(tx)
+}
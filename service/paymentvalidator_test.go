@@ -0,0 +1,267 @@
+package service_test
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+type utxoReaderFunc func(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error)
+
+func (u utxoReaderFunc) UTXO(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+	return u(ctx, txID, vout)
+}
+
+// utxos returns a reader of P2PKH utxos worth satoshis each.
+func utxos(satoshis uint64) dpp.UTXOReader {
+	return utxoReaderFunc(func(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+		script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+		if err != nil {
+			return nil, err
+		}
+		return &bt.UTXO{Vout: vout, LockingScript: script, Satoshis: satoshis}, nil
+	})
+}
+
+func TestPaymentValidator_PaymentCreate(t *testing.T) {
+	script, err := bscript.NewP2PKHFromAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payment, _ := paymentFromTx(t, []uint32{0}, 1000)
+	tests := map[string]struct {
+		destinations dpp.PaymentDestinations
+		inputValue   uint64
+		expErr       string
+		expCalls     int
+	}{
+		"payment satisfying the request should be passed on": {
+			destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+			expCalls:     1,
+		}, "payment not paying enough fees should be rejected": {
+			destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+			inputValue:   1000,
+			expErr:       "[fees: tx does not pay enough fees]",
+		}, "payment missing a data output should be rejected": {
+			destinations: dpp.PaymentDestinations{
+				Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}},
+				Data:    []dpp.DataOutput{{Pushes: []string{"6f72646572"}}},
+			},
+			expErr: "[destinations.data[0]: tx does not contain zero value data output]",
+		}, "payment underpaying should be rejected": {
+			destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 2000, LockingScript: script}}},
+			expErr:       "[destinations.outputs[0]: tx does not pay 2000 satoshis to output]",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			ctx := context.Background()
+			store := inmemory.NewStore()
+			_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+				Destinations: test.destinations,
+			})
+			is.NoErr(err)
+			svc := &mocks.PaymentServiceMock{
+				PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+					return &dpp.PaymentACK{ID: args.PaymentID}, nil
+				},
+			}
+			inputValue := test.inputValue
+			if inputValue == 0 {
+				inputValue = 10000
+			}
+			_, err = service.NewPaymentValidator(svc, store, utxos(inputValue), dpp.NewPaymentModes(dpp.HybridPaymentModeHandler{})).
+				PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+			if test.expErr != "" {
+				is.True(err != nil)
+				is.Equal(err.Error(), test.expErr)
+			} else {
+				is.NoErr(err)
+			}
+			is.Equal(len(svc.PaymentCreateCalls()), test.expCalls)
+		})
+	}
+}
+
+func TestPaymentValidator_PaymentCreate_Mode(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	script, err := bscript.NewP2PKHFromAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb")
+	is.NoErr(err)
+	dests := dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}}
+	hybrid, err := dpp.NewHybridPaymentMode(dests, nil, false)
+	is.NoErr(err)
+	hybridJSON, err := json.Marshal(hybrid)
+	is.NoErr(err)
+	store := inmemory.NewStore()
+	_, err = store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Destinations: dests,
+		Modes:        map[string]json.RawMessage{dpp.PaymentModeHybrid: hybridJSON},
+	})
+	is.NoErr(err)
+	_, err = store.InvoiceCreate(ctx, *dpp.NewInvoice("abc123", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+	payment, txID := paymentFromTx(t, []uint32{0}, 1000)
+	rawTx := *payment.RawTx
+	payment.RawTx = nil
+	payment.ModeID = dpp.PaymentModeHybrid
+	payment.Mode, err = json.Marshal(dpp.HybridPayment{OptionID: dpp.HybridDefaultOptionID, Transactions: []string{rawTx}})
+	is.NoErr(err)
+
+	ack, err := service.NewPaymentValidator(service.NewPayment(store, store), store, utxos(10000), dpp.NewPaymentModes(dpp.HybridPaymentModeHandler{})).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+	is.NoErr(err)
+	is.Equal(ack.Error, 0)
+	// the wrapped service receives the mode transaction as the rawTx.
+	page, err := store.Payments(ctx, dpp.PaymentsArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	is.Equal(len(page.Payments), 1)
+	is.Equal(page.Payments[0].TxID, txID)
+	is.Equal(*page.Payments[0].Payment.RawTx, rawTx)
+	is.Equal(page.Payments[0].Payment.ModeID, "")
+}
+
+// paymentWithAncestry returns a payment spending the first output, worth satoshis, of a parent
+// tx along with the hex encoded ancestry containing the parent.
+func paymentWithAncestry(t *testing.T, satoshis uint64) (dpp.Payment, string) {
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent := bt.NewTx()
+	if err := parent.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", 0, script.String(), satoshis+1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.PayTo(script, satoshis); err != nil {
+		t.Fatal(err)
+	}
+	tx := bt.NewTx()
+	if err := tx.From(parent.TxID(), 0, script.String(), satoshis); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1000); err != nil {
+		t.Fatal(err)
+	}
+	rawTx := tx.String()
+	bb := append([]byte{0x01, 0x01}, bt.VarInt(len(parent.Bytes())).Bytes()...)
+	bb = append(bb, parent.Bytes()...)
+	return dpp.Payment{
+		RawTx: &rawTx,
+		MerchantData: dpp.Merchant{
+			ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+		},
+	}, hex.EncodeToString(bb)
+}
+
+func TestPaymentValidator_PaymentCreate_Ancestry(t *testing.T) {
+	script, err := bscript.NewP2PKHFromAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		ancestryRequired bool
+		inputValue       uint64
+		ancestry         bool
+		storeUTXO        bool
+		expErr           string
+		expCalls         int
+	}{
+		"inputs read from the ancestry should be accepted": {
+			ancestryRequired: true,
+			inputValue:       10000,
+			ancestry:         true,
+			expCalls:         1,
+		}, "inputs read from the ancestry not paying enough fees should be rejected": {
+			ancestryRequired: true,
+			inputValue:       1000,
+			ancestry:         true,
+			expErr:           "[fees: tx does not pay enough fees]",
+		}, "missing ancestry should be rejected when required": {
+			ancestryRequired: true,
+			inputValue:       10000,
+			storeUTXO:        true,
+			expErr:           "[ancestry: ancestry is required by the payment request]",
+		}, "inputs read from the store should be accepted": {
+			inputValue: 10000,
+			storeUTXO:  true,
+			expCalls:   1,
+		}, "unknown inputs should be rejected": {
+			inputValue: 10000,
+			expErr:     "[inputs[0]: spends unknown output",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			ctx := context.Background()
+			store := inmemory.NewStore()
+			_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+				Destinations:     dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+				AncestryRequired: test.ancestryRequired,
+			})
+			is.NoErr(err)
+			payment, ancestry := paymentWithAncestry(t, test.inputValue)
+			if test.ancestry {
+				payment.Ancestry = &ancestry
+			}
+			if test.storeUTXO {
+				tx, err := bt.NewTxFromString(*payment.RawTx)
+				is.NoErr(err)
+				is.NoErr(store.UTXOCreate(ctx, &bt.UTXO{
+					TxID:          tx.Inputs[0].PreviousTxID(),
+					Vout:          0,
+					LockingScript: tx.Inputs[0].PreviousTxScript,
+					Satoshis:      test.inputValue,
+				}))
+			}
+			svc := &mocks.PaymentServiceMock{
+				PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+					return &dpp.PaymentACK{ID: args.PaymentID}, nil
+				},
+			}
+			_, err = service.NewPaymentValidator(svc, store, store, dpp.NewPaymentModes(dpp.HybridPaymentModeHandler{})).
+				PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+			if test.expErr != "" {
+				is.True(err != nil)
+				is.True(strings.HasPrefix(err.Error(), test.expErr))
+			} else {
+				is.NoErr(err)
+			}
+			is.Equal(len(svc.PaymentCreateCalls()), test.expCalls)
+		})
+	}
+}
+
+func TestPaymentValidator_PaymentCreate_NilUTXO(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	script, err := bscript.NewP2PKHFromAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb")
+	is.NoErr(err)
+	store := inmemory.NewStore()
+	_, err = store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+	})
+	is.NoErr(err)
+	svc := &mocks.PaymentServiceMock{}
+	payment, _ := paymentFromTx(t, []uint32{0}, 1000)
+	ur := utxoReaderFunc(func(ctx context.Context, txID string, vout uint32) (*bt.UTXO, error) {
+		return nil, nil
+	})
+	_, err = service.NewPaymentValidator(svc, store, ur, dpp.NewPaymentModes()).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+	is.True(err != nil)
+	is.Equal(len(svc.PaymentCreateCalls()), 0)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+
+	"github.com/libsv/go-dpp"
+)
+
+type peerChannelPayments struct {
+	svc dpp.PaymentService
+	pcc dpp.PeerChannelCreator
+	pcw dpp.PeerChannelWriter
+	ir  dpp.InvoiceReader
+	pcs dpp.PaymentChannelWriter
+}
+
+// NewPeerChannelPayments will wrap a PaymentService and open a peer channel for each accepted
+// payment that does not already have one. The payer is given a read only token in the PaymentACK
+// and the current invoice state is posted to the channel, NewPeerChannelProofs posts further updates.
+//
+// The payment has been accepted by the time the channel is opened so a failure to open it is not
+// returned, the ACK is returned without a PeerChannel instead.
+func NewPeerChannelPayments(svc dpp.PaymentService, pcc dpp.PeerChannelCreator, pcw dpp.PeerChannelWriter,
+	ir dpp.InvoiceReader, pcs dpp.PaymentChannelWriter) dpp.PaymentService {
+	return &peerChannelPayments{svc: svc, pcc: pcc, pcw: pcw, ir: ir, pcs: pcs}
+}
+
+// PaymentCreate will pass the payment to the wrapped service and open a channel if it is accepted.
+func (p *peerChannelPayments) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ack, err := p.svc.PaymentCreate(ctx, args, req)
+	if err != nil || ack == nil || ack.Error > 0 || ack.PeerChannel != nil {
+		return ack, err
+	}
+	if ch := p.open(ctx, args.PaymentID, ack.TxID); ch != nil {
+		ack.PeerChannel = ch
+	}
+	return ack, nil
+}
+
+// open creates the channel and read token, stores the owner channel and posts the
+// invoice state, it returns the payers channel or nil if it could not be opened.
+func (p *peerChannelPayments) open(ctx context.Context, paymentID, txID string) *dpp.PeerChannelData {
+	ch, err := p.pcc.PeerChannelCreate(ctx, dpp.PeerChannelCreate{})
+	if err != nil || len(ch.AccessTokens) == 0 {
+		return nil
+	}
+	read, err := p.pcc.PeerChannelTokenCreate(ctx, ch.ID, dpp.PeerChannelTokenCreate{
+		Description: "payer " + paymentID,
+		CanRead:     true,
+	})
+	if err != nil {
+		return nil
+	}
+	owner := ch.Data(ch.AccessTokens[0].Token)
+	if err := p.pcs.PaymentChannelCreate(ctx, dpp.PaymentChannel{
+		PaymentID: paymentID,
+		TxID:      txID,
+		Channel:   owner,
+	}); err != nil {
+		return nil
+	}
+	if inv, err := p.ir.Invoice(ctx, dpp.InvoiceArgs{PaymentID: paymentID}); err == nil {
+		_, _ = p.pcw.PeerChannelMessageCreate(ctx, owner, dpp.PeerChannelPaymentMessage{
+			Type:      dpp.PeerChannelMessageStatus,
+			PaymentID: paymentID,
+			TxID:      txID,
+			State:     inv.State,
+			Timestamp: time.Now().UTC(),
+		})
+	}
+	payer := ch.Data(read.Token)
+	return &payer
+}
+
+type peerChannelProofs struct {
+	svc dpp.ProofsService
+	pcr dpp.PaymentChannelReader
+	pcw dpp.PeerChannelWriter
+}
+
+// NewPeerChannelProofs will wrap a ProofsService and forward each accepted proof to the peer
+// channel opened for the payment by NewPeerChannelPayments, marking the payment as confirmed.
+//
+// The proof has been stored by the time it is forwarded so failures to post it are not returned.
+func NewPeerChannelProofs(svc dpp.ProofsService, pcr dpp.PaymentChannelReader, pcw dpp.PeerChannelWriter) dpp.ProofsService {
+	return &peerChannelProofs{svc: svc, pcr: pcr, pcw: pcw}
+}
+
+// Create will pass the proof to the wrapped service and forward it if accepted.
+func (p *peerChannelProofs) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	if err := p.svc.Create(ctx, args, req); err != nil {
+		return err
+	}
+	pc, err := p.pcr.PaymentChannel(ctx, args.TxID)
+	if err != nil {
+		return nil
+	}
+	_, _ = p.pcw.PeerChannelMessageCreate(ctx, pc.Channel, dpp.PeerChannelPaymentMessage{
+		Type:      dpp.PeerChannelMessageProof,
+		PaymentID: pc.PaymentID,
+		TxID:      args.TxID,
+		State:     dpp.InvoiceStateConfirmed,
+		Proof:     &req,
+		Timestamp: time.Now().UTC(),
+	})
+	return nil
+}
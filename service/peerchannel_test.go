@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/peerchannels"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestPeerChannels(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	srv := httptest.NewServer(peerchannels.NewServer("account"))
+	defer srv.Close()
+	client := peerchannels.NewClient(&peerchannels.Config{Host: srv.URL, Token: "account"})
+	store := inmemory.NewStore()
+	_, err := store.InvoiceCreate(ctx, *dpp.NewInvoice("abc123", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+
+	payments := service.NewPeerChannelPayments(service.NewPayment(store, store), client, client, store, store)
+	req, txID := paymentFromTx(t, []uint32{0}, 1000)
+	ack, err := payments.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, req)
+	is.NoErr(err)
+	is.True(ack.PeerChannel != nil)
+	is.Equal(ack.PeerChannel.Host, srv.URL)
+
+	proofs := service.NewPeerChannelProofs(&mocks.ProofsServiceMock{
+		CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+			return nil
+		},
+	}, store, client)
+	is.NoErr(proofs.Create(ctx, dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"}, envelope.JSONEnvelope{Payload: "{}"}))
+	// proofs for payments without a channel are not forwarded.
+	is.NoErr(proofs.Create(ctx, dpp.ProofCreateArgs{TxID: "unknown"}, envelope.JSONEnvelope{Payload: "{}"}))
+
+	msgs, err := client.PeerChannelMessages(ctx, *ack.PeerChannel, dpp.PeerChannelMessagesArgs{})
+	is.NoErr(err)
+	is.Equal(len(msgs), 2)
+	var status, proof dpp.PeerChannelPaymentMessage
+	is.NoErr(json.Unmarshal(msgs[0].Payload, &status))
+	is.Equal(status.Type, dpp.PeerChannelMessageStatus)
+	is.Equal(status.State, dpp.InvoiceStateBroadcast)
+	is.Equal(status.TxID, txID)
+	is.NoErr(json.Unmarshal(msgs[1].Payload, &proof))
+	is.Equal(proof.Type, dpp.PeerChannelMessageProof)
+	is.Equal(proof.State, dpp.InvoiceStateConfirmed)
+	is.Equal(proof.Proof.Payload, "{}")
+
+	// the payer can only read.
+	is.True(client.PeerChannelNotify(ctx, *ack.PeerChannel, "spoofed") != nil)
+}
+
+func TestPeerChannelPayments_Rejected(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	srv := httptest.NewServer(peerchannels.NewServer("account"))
+	defer srv.Close()
+	client := peerchannels.NewClient(&peerchannels.Config{Host: srv.URL, Token: "account"})
+	store := inmemory.NewStore()
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			return &dpp.PaymentACK{ID: args.PaymentID, TxID: "tx1", Error: 1}, nil
+		},
+	}
+	ack, err := service.NewPeerChannelPayments(svc, client, client, store, store).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{})
+	is.NoErr(err)
+	is.True(ack.PeerChannel == nil)
+	_, err = store.PaymentChannel(ctx, "tx1")
+	is.True(err != nil)
+}
+
+func TestPeerChannelPayments_NoACK(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	srv := httptest.NewServer(peerchannels.NewServer("account"))
+	defer srv.Close()
+	client := peerchannels.NewClient(&peerchannels.Config{Host: srv.URL, Token: "account"})
+	store := inmemory.NewStore()
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			return nil, nil
+		},
+	}
+	ack, err := service.NewPeerChannelPayments(svc, client, client, store, store).
+		PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{})
+	is.NoErr(err)
+	is.True(ack == nil)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type proofs struct {
+	pw  dpp.ProofsWriter
+	irw dpp.InvoiceReaderWriter
+	options
+}
+
+// NewProofs will setup and return a new ProofsService which validates and stores
+// merkle proofs, confirming the invoice they relate to.
+//
+// With WithEvents it publishes ProofReceived, with WithWebhooks it is queued as a webhook by the
+// ProofsWriter with each proof that has a PaymentReference, using ProofCreateArgs.Outbox. mAPI can
+// send more than one proof for a transaction so receivers can get more than one webhook.
+func NewProofs(pw dpp.ProofsWriter, irw dpp.InvoiceReaderWriter, opts ...Opt) dpp.ProofsService {
+	return &proofs{pw: pw, irw: irw, options: newOptions(opts)}
+}
+
+// Create will validate the envelope and its merkle proof payload before storing it. The envelope
+// must be signed, mAPI signs its callbacks, unsigned proofs are rejected.
+//
+// If a PaymentReference is supplied the related invoice must have been paid by the proof TxID
+// and is marked as confirmed, receiving a proof for an already confirmed invoice is not an error
+// as mAPI can send more than one, nor is a proof for an invoice that was fully refunded before
+// the payment confirmed.
+func (p *proofs) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	// IsValid accepts unsigned envelopes and panics if only one of these is supplied.
+	if req.Signature == nil || req.PublicKey == nil {
+		return validator.ErrValidation{"envelope": []string{"proof envelope must contain a signature and publicKey"}}
+	}
+	ok, err := req.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "failed to validate proof envelope")
+	}
+	if !ok {
+		return errors.New("proof envelope signature is invalid")
+	}
+	var proof dpp.ProofWrapper
+	if err := json.Unmarshal([]byte(req.Payload), &proof); err != nil {
+		return errors.Wrap(err, "failed to read proof payload")
+	}
+	if err := proof.Validate(args); err != nil {
+		return err
+	}
+	if err := p.paidBy(ctx, args); err != nil {
+		return err
+	}
+	e := dpp.ProofReceived{
+		PaymentID: args.PaymentReference,
+		TxID:      args.TxID,
+		Proof:     req,
+		Timestamp: time.Now().UTC(),
+	}
+	args.Outbox = nil
+	if args.PaymentReference != "" {
+		if args.Outbox, err = p.outbox(args.PaymentReference, e); err != nil {
+			return err
+		}
+	}
+	if err := p.pw.ProofCreate(ctx, args, req); err != nil {
+		return errors.Wrap(err, "failed to store proof")
+	}
+	if err := p.confirm(ctx, args); err != nil {
+		return err
+	}
+	p.publish(ctx, e)
+	return nil
+}
+
+// paidBy will ensure the invoice for the PaymentReference, if supplied, was paid by the proof tx
+// so a proof for one transaction cannot confirm another invoice.
+func (p *proofs) paidBy(ctx context.Context, args dpp.ProofCreateArgs) error {
+	if args.PaymentReference == "" {
+		return nil
+	}
+	inv, err := p.irw.Invoice(ctx, dpp.InvoiceArgs{PaymentID: args.PaymentReference})
+	if err != nil {
+		return errors.Wrapf(err, "failed to read invoice %s", args.PaymentReference)
+	}
+	if inv.TxID != args.TxID {
+		return validator.ErrValidation{"paymentReference": []string{
+			fmt.Sprintf("invoice %s was not paid by tx %s", args.PaymentReference, args.TxID),
+		}}
+	}
+	return nil
+}
+
+// confirm will mark the invoice for the PaymentReference, if supplied, as confirmed.
+func (p *proofs) confirm(ctx context.Context, args dpp.ProofCreateArgs) error {
+	if args.PaymentReference == "" {
+		return nil
+	}
+	if _, err := p.irw.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: args.PaymentReference}, dpp.InvoiceUpdate{
+		State:     dpp.InvoiceStateConfirmed,
+		TxID:      args.TxID,
+		Timestamp: time.Now().UTC(),
+	}); err != nil {
+		var errT dpp.InvoiceTransitionError
+		if errors.As(err, &errT) {
+			switch errT.From {
+			case dpp.InvoiceStateConfirmed, dpp.InvoiceStateRefunded:
+				return nil
+			}
+		}
+		return errors.Wrapf(err, "failed to confirm invoice %s", args.PaymentReference)
+	}
+	return nil
+}
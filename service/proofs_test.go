@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestProofs_Create(t *testing.T) {
+	ctx := context.Background()
+	const txID = "3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5"
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.New(t).NoErr(err)
+	signed := func(t *testing.T, txID string) envelope.JSONEnvelope {
+		env, err := dpp.NewSignedEnvelope(key, dpp.ProofWrapper{
+			CallbackPayload: &bc.MerkleProof{TxOrID: txID, Target: "abc", TargetType: "hash"},
+			BlockHash:       "abc",
+			CallbackTxID:    txID,
+			CallbackReason:  "merkleProof",
+		})
+		is.New(t).NoErr(err)
+		return *env
+	}
+	tests := map[string]struct {
+		args     dpp.ProofCreateArgs
+		envelope func(t *testing.T) envelope.JSONEnvelope
+		expErr   bool
+		expState dpp.InvoiceState
+	}{
+		"signed proof for the paying tx should confirm the invoice": {
+			args:     dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"},
+			envelope: func(t *testing.T) envelope.JSONEnvelope { return signed(t, txID) },
+			expState: dpp.InvoiceStateConfirmed,
+		}, "proof for another tx should not confirm the invoice": {
+			args: dpp.ProofCreateArgs{
+				TxID:             "b5a86d0a2d0a4b5bd8a7a3c34a0e4ec8b4e4e9e4e8a3e7b4b6e1d4c3e8b7a6c5",
+				PaymentReference: "abc123",
+			},
+			envelope: func(t *testing.T) envelope.JSONEnvelope {
+				return signed(t, "b5a86d0a2d0a4b5bd8a7a3c34a0e4ec8b4e4e9e4e8a3e7b4b6e1d4c3e8b7a6c5")
+			},
+			expErr:   true,
+			expState: dpp.InvoiceStateBroadcast,
+		}, "unsigned proof should be rejected": {
+			args: dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"},
+			envelope: func(t *testing.T) envelope.JSONEnvelope {
+				env := signed(t, txID)
+				env.Signature, env.PublicKey = nil, nil
+				return env
+			},
+			expErr:   true,
+			expState: dpp.InvoiceStateBroadcast,
+		}, "proof missing a signature should be rejected": {
+			args: dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"},
+			envelope: func(t *testing.T) envelope.JSONEnvelope {
+				env := signed(t, txID)
+				env.Signature = nil
+				return env
+			},
+			expErr:   true,
+			expState: dpp.InvoiceStateBroadcast,
+		}, "proof missing a publicKey should be rejected": {
+			args: dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"},
+			envelope: func(t *testing.T) envelope.JSONEnvelope {
+				env := signed(t, txID)
+				env.PublicKey = nil
+				return env
+			},
+			expErr:   true,
+			expState: dpp.InvoiceStateBroadcast,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			store := inmemory.NewStore()
+			_, err := store.InvoiceCreate(ctx, dpp.Invoice{ID: "abc123", State: dpp.InvoiceStateBroadcast, TxID: txID})
+			is.NoErr(err)
+
+			err = service.NewProofs(store, store).Create(ctx, test.args, test.envelope(t))
+			is.Equal(err != nil, test.expErr)
+			inv, err := store.Invoice(ctx, dpp.InvoiceArgs{PaymentID: "abc123"})
+			is.NoErr(err)
+			is.Equal(inv.State, test.expState)
+			is.Equal(inv.TxID, txID)
+			pp, err := store.Proofs(ctx, dpp.ProofsArgs{})
+			is.NoErr(err)
+			is.Equal(len(pp) == 1, !test.expErr)
+		})
+	}
+}
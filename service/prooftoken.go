@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type proofTokenIssuer struct {
+	svc dpp.PaymentService
+	ptw dpp.ProofTokenWriter
+}
+
+// NewProofTokenIssuer will wrap a PaymentService and issue a random proof callback token for
+// each payment. The token is stored against the txid before the payment is passed on, so
+// callbacks arriving as soon as the tx is broadcast are accepted, and is passed on in the args
+// so the PaymentWriter can supply it to mAPI when broadcasting.
+//
+// The payment must have a rawTx, so the issuer should be wrapped by the payment validator. It
+// should also be wrapped by the payment guard so a retried payment does not replace the token
+// issued for a tx that has already been broadcast.
+func NewProofTokenIssuer(svc dpp.PaymentService, ptw dpp.ProofTokenWriter) dpp.PaymentService {
+	return &proofTokenIssuer{svc: svc, ptw: ptw}
+}
+
+// PaymentCreate will issue and store a token then pass the payment to the wrapped service.
+func (p *proofTokenIssuer) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if req.RawTx == nil {
+		return nil, validator.ErrValidation{"rawTx": []string{"rawTx is required to issue a proof token"}}
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, validator.ErrValidation{"rawTx": []string{"invalid rawTx supplied"}}
+	}
+	token, err := dpp.NewProofToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.ptw.ProofTokenCreate(ctx, dpp.ProofToken{
+		TxID:      tx.TxID(),
+		PaymentID: args.PaymentID,
+		Token:     token,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to store proof token for tx %s", tx.TxID())
+	}
+	args.ProofToken = token
+	return p.svc.PaymentCreate(ctx, args, req)
+}
+
+type proofTokenVerifier struct {
+	svc dpp.ProofsService
+	ptr dpp.ProofTokenReader
+}
+
+// NewProofTokenVerifier will wrap a ProofsService and reject proofs that do not supply the
+// token issued for their transaction before the envelope is read. The PaymentReference, if
+// supplied, must be the payment the token was issued for.
+func NewProofTokenVerifier(svc dpp.ProofsService, ptr dpp.ProofTokenReader) dpp.ProofsService {
+	return &proofTokenVerifier{svc: svc, ptr: ptr}
+}
+
+// Create will check the token and pass the proof to the wrapped service.
+func (p *proofTokenVerifier) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	if args.Token == "" {
+		return dpp.ProofTokenError{TxID: args.TxID}
+	}
+	token, err := p.ptr.ProofToken(ctx, args.TxID)
+	if err != nil {
+		if errors.Is(err, dpp.ErrProofTokenNotFound) {
+			return dpp.ProofTokenError{TxID: args.TxID}
+		}
+		return errors.Wrapf(err, "failed to read proof token for tx %s", args.TxID)
+	}
+	if !token.Matches(args.Token) {
+		return dpp.ProofTokenError{TxID: args.TxID}
+	}
+	if args.PaymentReference != "" && args.PaymentReference != token.PaymentID {
+		return dpp.ProofTokenError{TxID: args.TxID}
+	}
+	return p.svc.Create(ctx, args, req)
+}
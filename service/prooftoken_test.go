@@ -0,0 +1,97 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestProofTokenIssuer_PaymentCreate(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	tx, err := bt.NewTxFromString(rawTx)
+	is.NoErr(err)
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			// the token is stored before the payment is broadcast.
+			token, err := store.ProofToken(ctx, tx.TxID())
+			is.NoErr(err)
+			is.Equal(token.PaymentID, "abc123")
+			is.Equal(token.Token, args.ProofToken)
+			return &dpp.PaymentACK{ID: args.PaymentID, TxID: tx.TxID()}, nil
+		},
+	}
+	issuer := service.NewProofTokenIssuer(svc, store)
+
+	_, err = issuer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, validPayment())
+	is.NoErr(err)
+	is.Equal(len(svc.PaymentCreateCalls()), 1)
+	_, err = issuer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, validPayment())
+	is.NoErr(err)
+	is.True(svc.PaymentCreateCalls()[0].Args.ProofToken != svc.PaymentCreateCalls()[1].Args.ProofToken)
+
+	// a token cannot be issued without a rawTx.
+	_, err = issuer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{})
+	is.True(err != nil)
+	is.Equal(len(svc.PaymentCreateCalls()), 2)
+}
+
+func TestProofTokenVerifier_Create(t *testing.T) {
+	ctx := context.Background()
+	tests := map[string]struct {
+		args     dpp.ProofCreateArgs
+		expErr   bool
+		expCalls int
+	}{
+		"matching token should be accepted": {
+			args:     dpp.ProofCreateArgs{TxID: "tx1", Token: "secret"},
+			expCalls: 1,
+		}, "missing token should be rejected": {
+			args:   dpp.ProofCreateArgs{TxID: "tx1"},
+			expErr: true,
+		}, "wrong token should be rejected": {
+			args:   dpp.ProofCreateArgs{TxID: "tx1", Token: "guess"},
+			expErr: true,
+		}, "token for another tx should be rejected": {
+			args:   dpp.ProofCreateArgs{TxID: "tx2", Token: "secret"},
+			expErr: true,
+		}, "matching payment reference should be accepted": {
+			args:     dpp.ProofCreateArgs{TxID: "tx1", Token: "secret", PaymentReference: "abc123"},
+			expCalls: 1,
+		}, "token for another payment should be rejected": {
+			args:   dpp.ProofCreateArgs{TxID: "tx1", Token: "secret", PaymentReference: "def456"},
+			expErr: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			store := inmemory.NewStore()
+			is.NoErr(store.ProofTokenCreate(ctx, dpp.ProofToken{TxID: "tx1", PaymentID: "abc123", Token: "secret"}))
+			svc := &mocks.ProofsServiceMock{
+				CreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+					return nil
+				},
+			}
+			err := service.NewProofTokenVerifier(svc, store).Create(ctx, test.args, envelope.JSONEnvelope{})
+			is.Equal(len(svc.CreateCalls()), test.expCalls)
+			if test.expErr {
+				var errToken dpp.ProofTokenError
+				is.True(errors.As(err, &errToken))
+				is.Equal(errToken.TxID, test.args.TxID)
+				return
+			}
+			is.NoErr(err)
+		})
+	}
+}
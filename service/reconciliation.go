@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type reconciliation struct {
+	ir  dpp.InvoiceReader
+	prr dpp.PaymentRequestReader
+	pr  dpp.PaymentReader
+	pfr dpp.ProofsReader
+}
+
+// NewReconciliation will setup and return a new ReconciliationService.
+func NewReconciliation(ir dpp.InvoiceReader, prr dpp.PaymentRequestReader, pr dpp.PaymentReader, pfr dpp.ProofsReader) dpp.ReconciliationService {
+	return &reconciliation{ir: ir, prr: prr, pr: pr, pfr: pfr}
+}
+
+// Reconcile will produce a report for every invoice created in the period along with
+// every proof received in the period that cannot be matched to a payment or invoice.
+//
+// Payments made at any time after the start of the period are considered so invoices
+// paid shortly after the period closes are not reported as unpaid.
+func (r *reconciliation) Reconcile(ctx context.Context, args dpp.ReconciliationArgs) (*dpp.ReconciliationReport, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	invoices, err := r.ir.Invoices(ctx, dpp.InvoicesArgs{From: args.From, To: args.To})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read invoices")
+	}
+	payments, err := r.payments(ctx, dpp.PaymentsArgs{From: args.From})
+	if err != nil {
+		return nil, err
+	}
+	byInvoice := map[string][]dpp.PaymentRecord{}
+	paid := make(map[string]bool, len(payments))
+	for _, p := range payments {
+		byInvoice[p.PaymentID] = append(byInvoice[p.PaymentID], p)
+		paid[p.TxID] = true
+	}
+	report := &dpp.ReconciliationReport{
+		From:        args.From,
+		To:          args.To,
+		GeneratedAt: time.Now().UTC(),
+		Totals:      map[dpp.ReconciliationStatus]int{},
+		Entries:     []dpp.ReconciliationEntry{},
+	}
+	// proofs are read once for every payment considered, those received before the end of the
+	// period that cannot be matched are reported.
+	proofs, err := r.pfr.Proofs(ctx, dpp.ProofsArgs{From: args.From})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read proofs")
+	}
+	proved := make(map[string]bool, len(proofs))
+	for _, p := range proofs {
+		proved[p.TxID] = true
+	}
+	for _, inv := range invoices {
+		entry, err := r.reconcileInvoice(ctx, inv, byInvoice[inv.ID], proved)
+		if err != nil {
+			return nil, err
+		}
+		report.Entries = append(report.Entries, *entry)
+		report.Totals[entry.Status]++
+	}
+	for _, p := range proofs {
+		if paid[p.TxID] || !p.CreatedAt.Before(args.To) {
+			continue
+		}
+		matched, err := r.proofMatched(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			continue
+		}
+		report.Entries = append(report.Entries, dpp.ReconciliationEntry{
+			Status:    dpp.ReconciliationStatusProofWithoutInvoice,
+			PaymentID: p.PaymentReference,
+			TxIDs:     []string{p.TxID},
+			Confirmed: true,
+			CreatedAt: p.CreatedAt,
+		})
+		report.Totals[dpp.ReconciliationStatusProofWithoutInvoice]++
+	}
+	return report, nil
+}
+
+func (r *reconciliation) reconcileInvoice(ctx context.Context, inv dpp.Invoice, payments []dpp.PaymentRecord, proved map[string]bool) (*dpp.ReconciliationEntry, error) {
+	pr, err := r.prr.PaymentRequest(dpp.WithInternalRead(ctx), dpp.PaymentRequestArgs{PaymentID: inv.ID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read payment request %s", inv.ID)
+	}
+	entry := &dpp.ReconciliationEntry{
+		PaymentID:    inv.ID,
+		InvoiceState: inv.State,
+		TxIDs:        []string{},
+		Confirmed:    len(payments) > 0,
+		CreatedAt:    inv.CreatedAt,
+	}
+	outputs := pr.Destinations.Outputs
+	for _, p := range payments {
+		tx, err := paymentTx(p)
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			continue
+		}
+		if oo, ok := modeOutputs(*pr, tx); ok {
+			outputs = oo
+		}
+		break
+	}
+	for _, o := range outputs {
+		entry.Expected += o.Amount
+	}
+	for _, p := range payments {
+		received, err := paidTo(p, outputs)
+		if err != nil {
+			return nil, err
+		}
+		entry.Received += received
+		entry.TxIDs = append(entry.TxIDs, p.TxID)
+		if !proved[p.TxID] {
+			entry.Confirmed = false
+		}
+	}
+	switch {
+	case len(payments) == 0:
+		entry.Status = dpp.ReconciliationStatusUnpaid
+	case entry.Received < entry.Expected:
+		entry.Status = dpp.ReconciliationStatusUnderpaid
+	case entry.Received > entry.Expected:
+		entry.Status = dpp.ReconciliationStatusOverpaid
+	case !entry.Confirmed:
+		entry.Status = dpp.ReconciliationStatusUnconfirmed
+	default:
+		entry.Status = dpp.ReconciliationStatusReconciled
+	}
+	return entry, nil
+}
+
+// modeOutputs returns the outputs of the HybridPaymentMode option paid by the tx, used when the tx
+// does not pay the request destinations. Only single transaction options can be paid.
+func modeOutputs(pr dpp.PaymentRequest, tx *bt.Tx) ([]dpp.Output, bool) {
+	if pays(tx, pr.Destinations.Outputs) {
+		return nil, false
+	}
+	raw, ok := pr.Modes[dpp.PaymentModeHybrid]
+	if !ok {
+		return nil, false
+	}
+	var mode dpp.HybridPaymentMode
+	if err := json.Unmarshal(raw, &mode); err != nil {
+		return nil, false
+	}
+	ids := make([]string, 0, len(mode))
+	for id := range mode {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		option := mode[id]
+		if len(option.Transactions) == 1 && pays(tx, option.Transactions[0].Outputs.Native) {
+			return option.Transactions[0].Outputs.Native, true
+		}
+	}
+	return nil, false
+}
+
+// pays returns true if the tx has an output for every output script, the amounts are not
+// checked so underpayments are still matched.
+func pays(tx *bt.Tx, outputs []dpp.Output) bool {
+	for _, o := range outputs {
+		if o.LockingScript == nil {
+			continue
+		}
+		found := false
+		for _, txo := range tx.Outputs {
+			if txo.LockingScript.Equals(o.LockingScript) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// paidTo returns the satoshis the payment tx paid to the outputs, payments without a rawTx use
+// the amount recorded by the store.
+func paidTo(p dpp.PaymentRecord, outputs []dpp.Output) (uint64, error) {
+	tx, err := paymentTx(p)
+	if err != nil || tx == nil {
+		return p.Amount, err
+	}
+	var sats uint64
+	for _, txo := range tx.Outputs {
+		for _, o := range outputs {
+			if o.LockingScript != nil && o.LockingScript.Equals(txo.LockingScript) {
+				sats += txo.Satoshis
+				break
+			}
+		}
+	}
+	return sats, nil
+}
+
+// paymentTx parses the payment rawTx, nil is returned if the payment has none.
+func paymentTx(p dpp.PaymentRecord) (*bt.Tx, error) {
+	if p.Payment.RawTx == nil {
+		return nil, nil
+	}
+	tx, err := bt.NewTxFromString(*p.Payment.RawTx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid rawTx stored for payment %s", p.TxID)
+	}
+	return tx, nil
+}
+
+// proofMatched returns true if the proof relates to a stored payment or a known invoice.
+func (r *reconciliation) proofMatched(ctx context.Context, p dpp.ProofRecord) (bool, error) {
+	page, err := r.pr.Payments(ctx, dpp.PaymentsArgs{TxID: p.TxID, Limit: 1})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to read payments for %s", p.TxID)
+	}
+	if len(page.Payments) > 0 {
+		return true, nil
+	}
+	if p.PaymentReference == "" {
+		return false, nil
+	}
+	if _, err := r.ir.Invoice(ctx, dpp.InvoiceArgs{PaymentID: p.PaymentReference}); err != nil {
+		if errors.Is(err, dpp.ErrInvoiceNotFound) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to read invoice %s", p.PaymentReference)
+	}
+	return true, nil
+}
+
+// payments reads every page of payments matching the args.
+func (r *reconciliation) payments(ctx context.Context, args dpp.PaymentsArgs) ([]dpp.PaymentRecord, error) {
+	args.Limit = dpp.PaymentsMaxLimit
+	pp := make([]dpp.PaymentRecord, 0)
+	for {
+		page, err := r.pr.Payments(ctx, args)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read payments")
+		}
+		pp = append(pp, page.Payments...)
+		if page.NextCursor == "" {
+			return pp, nil
+		}
+		args.Cursor = page.NextCursor
+	}
+}
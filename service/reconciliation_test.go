@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestReconciliation_Reconcile(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	start := time.Now().UTC().Add(-time.Minute)
+
+	// paid is the amount paid to each invoice, -1 means no payment.
+	invoices := map[string]struct {
+		paid  int
+		proof bool
+	}{
+		"unpaid":      {paid: -1},
+		"underpaid":   {paid: 500},
+		"overpaid":    {paid: 1500},
+		"unconfirmed": {paid: 1000},
+		"reconciled":  {paid: 1000, proof: true},
+	}
+	var vout uint32
+	for id, inv := range invoices {
+		_, err := store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: id}, dpp.PaymentRequest{
+			Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+		})
+		is.NoErr(err)
+		_, err = store.InvoiceCreate(ctx, *dpp.NewInvoice(id, time.Now().UTC(), time.Time{}))
+		is.NoErr(err)
+		if inv.paid < 0 {
+			continue
+		}
+		tx := bt.NewTx()
+		vout++
+		is.NoErr(tx.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", vout, script.String(), 10000))
+		is.NoErr(tx.PayTo(script, uint64(inv.paid)))
+		rawTx := tx.String()
+		_, err = store.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: id}, dpp.Payment{RawTx: &rawTx})
+		is.NoErr(err)
+		if inv.proof {
+			is.NoErr(store.ProofCreate(ctx, dpp.ProofCreateArgs{TxID: tx.TxID(), PaymentReference: id}, envelope.JSONEnvelope{}))
+		}
+	}
+	is.NoErr(store.ProofCreate(ctx, dpp.ProofCreateArgs{TxID: "unknown"}, envelope.JSONEnvelope{}))
+
+	svc := service.NewReconciliation(store, store, store, store)
+	report, err := svc.Reconcile(ctx, dpp.ReconciliationArgs{From: start, To: time.Now().UTC().Add(time.Minute)})
+	is.NoErr(err)
+	is.Equal(len(report.Entries), 6)
+	statuses := map[string]dpp.ReconciliationStatus{}
+	for _, e := range report.Entries {
+		statuses[e.PaymentID] = e.Status
+	}
+	is.Equal(statuses, map[string]dpp.ReconciliationStatus{
+		"unpaid":      dpp.ReconciliationStatusUnpaid,
+		"underpaid":   dpp.ReconciliationStatusUnderpaid,
+		"overpaid":    dpp.ReconciliationStatusOverpaid,
+		"unconfirmed": dpp.ReconciliationStatusUnconfirmed,
+		"reconciled":  dpp.ReconciliationStatusReconciled,
+		"":            dpp.ReconciliationStatusProofWithoutInvoice,
+	})
+	is.Equal(report.Totals[dpp.ReconciliationStatusUnderpaid], 1)
+
+	var buf bytes.Buffer
+	is.NoErr(report.WriteCSV(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	is.NoErr(err)
+	is.Equal(len(rows), 7)
+	is.Equal(rows[0][0], "status")
+
+	_, err = svc.Reconcile(ctx, dpp.ReconciliationArgs{From: start, To: start.Add(-time.Hour)})
+	is.True(err != nil)
+}
+
+func TestReconciliation_Reconcile_Mode(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	optionScript, err := bscript.NewFromHexString("76a914b3e1cac1ae9e4f9b1ac0d0b3e3b8b0c3f7c5c2b888ac")
+	is.NoErr(err)
+	start := time.Now().UTC().Add(-time.Minute)
+
+	mode, err := json.Marshal(dpp.HybridPaymentMode{"choiceID1": {Transactions: []dpp.HybridTransaction{{
+		Outputs: dpp.HybridOutputs{Native: []dpp.Output{{Amount: 2000, LockingScript: optionScript}}},
+	}}}})
+	is.NoErr(err)
+	_, err = store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+		Modes:        map[string]json.RawMessage{dpp.PaymentModeHybrid: mode},
+	})
+	is.NoErr(err)
+	_, err = store.InvoiceCreate(ctx, *dpp.NewInvoice("abc123", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+	tx := bt.NewTx()
+	is.NoErr(tx.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", 0, script.String(), 10000))
+	is.NoErr(tx.PayTo(optionScript, 2000))
+	rawTx := tx.String()
+	_, err = store.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, dpp.Payment{RawTx: &rawTx})
+	is.NoErr(err)
+	is.NoErr(store.ProofCreate(ctx, dpp.ProofCreateArgs{TxID: tx.TxID(), PaymentReference: "abc123"}, envelope.JSONEnvelope{}))
+
+	report, err := service.NewReconciliation(store, store, store, store).
+		Reconcile(ctx, dpp.ReconciliationArgs{From: start, To: time.Now().UTC().Add(time.Minute)})
+	is.NoErr(err)
+	is.Equal(len(report.Entries), 1)
+	is.Equal(report.Entries[0].Status, dpp.ReconciliationStatusReconciled)
+	is.Equal(report.Entries[0].Expected, uint64(2000))
+	is.Equal(report.Entries[0].Received, uint64(2000))
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/libsv/go-dpp"
+)
+
+type refund struct {
+	// mu serialises refunds so concurrent requests cannot refund more than was paid.
+	mu     sync.Mutex
+	pr     dpp.PaymentReader
+	rrw    dpp.RefundReaderWriter
+	irw    dpp.InvoiceReaderWriter
+	res    dpp.PaymailResolver
+	signer dpp.RefundSigner
+	b      dpp.TransactionBroadcaster
+	pcr    dpp.PaymentChannelReader
+	pcn    dpp.PeerChannelNotifier
+}
+
+// NewRefund will setup and return a new RefundService which pays refunds to the paymail
+// supplied as the Payment RefundTo.
+//
+// The pcr and pcn are optional, when both are supplied the payer is notified of refunds over
+// the peer channel opened for the payment by NewPeerChannelPayments.
+func NewRefund(pr dpp.PaymentReader, rrw dpp.RefundReaderWriter, irw dpp.InvoiceReaderWriter, res dpp.PaymailResolver,
+	signer dpp.RefundSigner, b dpp.TransactionBroadcaster, pcr dpp.PaymentChannelReader, pcn dpp.PeerChannelNotifier) dpp.RefundService {
+	return &refund{pr: pr, rrw: rrw, irw: irw, res: res, signer: signer, b: b, pcr: pcr, pcn: pcn}
+}
+
+// RefundCreate will resolve the RefundTo paymail, have the wallet fund and sign a transaction
+// paying it and broadcast it. The refund is stored as pending before it is broadcast so it is
+// recorded even if the process stops, then marked as broadcast or failed. The invoice is then
+// moved to refunded once the full amount paid has been refunded, or partially_refunded if not.
+func (r *refund) RefundCreate(ctx context.Context, args dpp.RefundArgs, req dpp.RefundCreate) (*dpp.Refund, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	inv, err := r.irw.Invoice(ctx, dpp.InvoiceArgs{PaymentID: args.PaymentID})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read invoice %s", args.PaymentID)
+	}
+	if !inv.State.CanTransition(dpp.InvoiceStatePartiallyRefunded) {
+		return nil, dpp.InvoiceTransitionError{PaymentID: inv.ID, From: inv.State, To: dpp.InvoiceStatePartiallyRefunded}
+	}
+	payment, err := r.payment(ctx, inv)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Payment.RefundTo == nil || *payment.Payment.RefundTo == "" {
+		return nil, validator.ErrValidation{"refundTo": []string{"payment has no refundTo paymail"}}
+	}
+	refunds, err := r.rrw.Refunds(ctx, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read refunds for payment %s", args.PaymentID)
+	}
+	var refunded uint64
+	for _, rf := range refunds {
+		if rf.Status != dpp.RefundStatusFailed {
+			refunded += rf.Amount
+		}
+	}
+	remaining := payment.Amount - refunded
+	if refunded > payment.Amount || req.Amount > remaining {
+		return nil, dpp.RefundExceededError{PaymentID: args.PaymentID, Amount: req.Amount, Remaining: remaining}
+	}
+	dest, err := r.res.PaymailDestination(ctx, dpp.PaymailDestinationArgs{
+		Paymail:  *payment.Payment.RefundTo,
+		Satoshis: req.Amount,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve refundTo %s", *payment.Payment.RefundTo)
+	}
+	if err := validateRefundOutputs(dest.Outputs, req.Amount); err != nil {
+		return nil, errors.Wrapf(err, "invalid destination for refundTo %s", *payment.Payment.RefundTo)
+	}
+	tx, err := r.signer.RefundTx(ctx, dest.Outputs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign refund tx")
+	}
+	rf := dpp.Refund{
+		PaymentID:   args.PaymentID,
+		PaymentTxID: payment.TxID,
+		TxID:        tx.TxID(),
+		Amount:      req.Amount,
+		Reason:      req.Reason,
+		RefundTo:    *payment.Payment.RefundTo,
+		Reference:   dest.Reference,
+		Status:      dpp.RefundStatusPending,
+		CreatedAt:   time.Now().UTC(),
+	}
+	if err := r.rrw.RefundCreate(ctx, rf); err != nil {
+		return nil, errors.Wrapf(err, "failed to store pending refund tx %s", rf.TxID)
+	}
+	if err := r.b.Broadcast(ctx, tx); err != nil {
+		rf.Status = dpp.RefundStatusFailed
+		if uErr := r.rrw.RefundUpdate(ctx, rf); uErr != nil {
+			return nil, errors.Wrapf(uErr, "failed to mark refund tx %s as failed after broadcast error %s", rf.TxID, err)
+		}
+		return nil, errors.Wrapf(err, "failed to broadcast refund tx %s", rf.TxID)
+	}
+	rf.Status = dpp.RefundStatusBroadcast
+	rf.Notified = r.notify(ctx, rf)
+	if err := r.rrw.RefundUpdate(ctx, rf); err != nil {
+		return nil, errors.Wrapf(err, "failed to mark refund tx %s as broadcast", rf.TxID)
+	}
+	state := dpp.InvoiceStatePartiallyRefunded
+	if req.Amount == remaining {
+		state = dpp.InvoiceStateRefunded
+	}
+	if _, err := r.irw.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: args.PaymentID}, dpp.InvoiceUpdate{
+		State:     state,
+		Timestamp: rf.CreatedAt,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to mark invoice %s as %s", args.PaymentID, state)
+	}
+	return &rf, nil
+}
+
+// validateRefundOutputs will ensure the paymail host returned outputs paying exactly the refund amount.
+func validateRefundOutputs(outputs []dpp.Output, amount uint64) error {
+	if len(outputs) == 0 {
+		return errors.New("paymail destination contains no outputs")
+	}
+	var total uint64
+	for _, o := range outputs {
+		if o.Amount > amount-total {
+			return errors.Errorf("paymail destination outputs total more than the %d satoshis refunded", amount)
+		}
+		total += o.Amount
+	}
+	if total != amount {
+		return errors.Errorf("paymail destination outputs total %d satoshis rather than the %d satoshis refunded", total, amount)
+	}
+	return nil
+}
+
+// Refunds will return the refunds made for a payment.
+func (r *refund) Refunds(ctx context.Context, args dpp.RefundArgs) ([]dpp.Refund, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	return r.rrw.Refunds(ctx, args)
+}
+
+// payment returns the stored payment that paid the invoice.
+func (r *refund) payment(ctx context.Context, inv *dpp.Invoice) (*dpp.PaymentRecord, error) {
+	page, err := r.pr.Payments(ctx, dpp.PaymentsArgs{PaymentID: inv.ID, TxID: inv.TxID, Limit: 1})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read payments for invoice %s", inv.ID)
+	}
+	if len(page.Payments) == 0 {
+		return nil, errors.Wrapf(dpp.ErrPaymentNotFound, "invoice %s", inv.ID)
+	}
+	return &page.Payments[0], nil
+}
+
+// notify will post the refund to the payers peer channel, returning true if it was sent.
+// The refund has been broadcast so failures are not returned, the payer can still see the
+// refund on chain.
+func (r *refund) notify(ctx context.Context, rf dpp.Refund) bool {
+	if r.pcr == nil || r.pcn == nil {
+		return false
+	}
+	pc, err := r.pcr.PaymentChannel(ctx, rf.PaymentTxID)
+	if err != nil {
+		return false
+	}
+	return r.pcn.PeerChannelNotify(ctx, pc.Channel, dpp.RefundNotification{
+		PaymentID: rf.PaymentID,
+		TxID:      rf.TxID,
+		Amount:    rf.Amount,
+		Reason:    rf.Reason,
+	}) == nil
+}
@@ -0,0 +1,254 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/paymail/paymailtest"
+	"github.com/libsv/go-dpp/service"
+)
+
+type refundSignerFunc func(ctx context.Context, outputs []dpp.Output) (*bt.Tx, error)
+
+func (f refundSignerFunc) RefundTx(ctx context.Context, outputs []dpp.Output) (*bt.Tx, error) {
+	return f(ctx, outputs)
+}
+
+type broadcasterFunc func(ctx context.Context, tx *bt.Tx) error
+
+func (f broadcasterFunc) Broadcast(ctx context.Context, tx *bt.Tx) error {
+	return f(ctx, tx)
+}
+
+type resolverFunc func(ctx context.Context, args dpp.PaymailDestinationArgs) (*dpp.PaymailDestination, error)
+
+func (f resolverFunc) PaymailDestination(ctx context.Context, args dpp.PaymailDestinationArgs) (*dpp.PaymailDestination, error) {
+	return f(ctx, args)
+}
+
+type notifierFunc func(ctx context.Context, channel dpp.PeerChannelData, msg interface{}) error
+
+func (f notifierFunc) PeerChannelNotify(ctx context.Context, channel dpp.PeerChannelData, msg interface{}) error {
+	return f(ctx, channel, msg)
+}
+
+// paidInvoice stores a broadcast invoice paid with 1000 satoshis along with its payment and peer channel.
+func paidInvoice(t *testing.T, store *inmemory.Store, paymentID string, vout uint32, refundTo *string) {
+	is := is.New(t)
+	ctx := context.Background()
+	req, txID := paymentFromTx(t, []uint32{vout}, 1000)
+	req.RefundTo = refundTo
+	_, err := store.InvoiceCreate(ctx, *dpp.NewInvoice(paymentID, time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+	for _, state := range []dpp.InvoiceState{dpp.InvoiceStatePending, dpp.InvoiceStatePaid, dpp.InvoiceStateBroadcast} {
+		_, err = store.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: paymentID}, dpp.InvoiceUpdate{
+			State: state, TxID: txID, Timestamp: time.Now().UTC(),
+		})
+		is.NoErr(err)
+	}
+	_, err = store.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: paymentID}, req)
+	is.NoErr(err)
+	is.NoErr(store.PaymentChannelCreate(ctx, dpp.PaymentChannel{
+		PaymentID: paymentID,
+		TxID:      txID,
+		Channel:   dpp.PeerChannelData{Host: "peers", ChannelID: "channel1", Token: "token1"},
+	}))
+}
+
+func TestRefund_RefundCreate(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	resolver := paymailtest.NewResolver()
+	resolver.Add("me@paymail.com", script)
+	refundTo := "me@paymail.com"
+
+	store := inmemory.NewStore()
+	paidInvoice(t, store, "paid", 0, &refundTo)
+	paidInvoice(t, store, "norefund", 1, nil)
+	_, err = store.InvoiceCreate(ctx, *dpp.NewInvoice("unpaid", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+
+	var broadcast []*bt.Tx
+	var notified []interface{}
+	svc := service.NewRefund(store, store, store, resolver,
+		refundSignerFunc(func(ctx context.Context, outputs []dpp.Output) (*bt.Tx, error) {
+			tx := bt.NewTx()
+			for _, o := range outputs {
+				tx.AddOutput(&bt.Output{Satoshis: o.Amount, LockingScript: o.LockingScript})
+			}
+			return tx, nil
+		}),
+		broadcasterFunc(func(ctx context.Context, tx *bt.Tx) error {
+			// the refund is recorded before it is broadcast.
+			refunds, err := store.Refunds(ctx, dpp.RefundArgs{PaymentID: "paid"})
+			is.NoErr(err)
+			is.Equal(refunds[len(refunds)-1].TxID, tx.TxID())
+			is.Equal(refunds[len(refunds)-1].Status, dpp.RefundStatusPending)
+			broadcast = append(broadcast, tx)
+			return nil
+		}),
+		store,
+		notifierFunc(func(ctx context.Context, channel dpp.PeerChannelData, msg interface{}) error {
+			is.Equal(channel.ChannelID, "channel1")
+			notified = append(notified, msg)
+			return nil
+		}))
+
+	rf, err := svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 400, Reason: "one returned"})
+	is.NoErr(err)
+	is.Equal(rf.Amount, uint64(400))
+	is.Equal(rf.RefundTo, refundTo)
+	is.Equal(rf.Reference, "ref1")
+	is.True(rf.Notified)
+	is.Equal(rf.Status, dpp.RefundStatusBroadcast)
+	is.Equal(len(broadcast), 1)
+	is.Equal(broadcast[0].Outputs[0].Satoshis, uint64(400))
+	is.Equal(rf.TxID, broadcast[0].TxID())
+	is.Equal(notified[0], dpp.RefundNotification{PaymentID: "paid", TxID: rf.TxID, Amount: 400, Reason: "one returned"})
+	inv, err := store.Invoice(ctx, dpp.InvoiceArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(inv.State, dpp.InvoiceStatePartiallyRefunded)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 601, Reason: "rest returned"})
+	var errExceeded dpp.RefundExceededError
+	is.True(errors.As(err, &errExceeded))
+	is.Equal(errExceeded.Remaining, uint64(600))
+	is.Equal(len(broadcast), 1)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 600, Reason: "rest returned"})
+	is.NoErr(err)
+	inv, err = store.Invoice(ctx, dpp.InvoiceArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(inv.State, dpp.InvoiceStateRefunded)
+	refunds, err := svc.Refunds(ctx, dpp.RefundArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(len(refunds), 2)
+	is.Equal(refunds[0].Status, dpp.RefundStatusBroadcast)
+	is.True(refunds[0].Notified)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 1, Reason: "again"})
+	var errT dpp.InvoiceTransitionError
+	is.True(errors.As(err, &errT))
+	is.Equal(errT.From, dpp.InvoiceStateRefunded)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "unpaid"}, dpp.RefundCreate{Amount: 1, Reason: "unpaid"})
+	is.True(errors.As(err, &errT))
+	is.Equal(errT.From, dpp.InvoiceStateCreated)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "norefund"}, dpp.RefundCreate{Amount: 1, Reason: "no paymail"})
+	is.Equal(err.Error(), "[refundTo: payment has no refundTo paymail]")
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{})
+	is.Equal(err.Error(), "[amount: value 0 should be greater than 0], [reason: value must be between 1 and 250 characters]")
+	is.Equal(len(broadcast), 2)
+}
+
+func TestRefund_RefundCreate_InvalidDestination(t *testing.T) {
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		outputs []dpp.Output
+		expErr  string
+	}{
+		"resolver asking for more than the refund should be rejected": {
+			outputs: []dpp.Output{{Amount: 400, LockingScript: script}, {Amount: 200, LockingScript: script}},
+			expErr:  "invalid destination for refundTo me@paymail.com: paymail destination outputs total more than the 500 satoshis refunded",
+		}, "resolver asking for less than the refund should be rejected": {
+			outputs: []dpp.Output{{Amount: 400, LockingScript: script}},
+			expErr:  "invalid destination for refundTo me@paymail.com: paymail destination outputs total 400 satoshis rather than the 500 satoshis refunded",
+		}, "resolver returning no outputs should be rejected": {
+			expErr: "invalid destination for refundTo me@paymail.com: paymail destination contains no outputs",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.New(t)
+			ctx := context.Background()
+			refundTo := "me@paymail.com"
+			store := inmemory.NewStore()
+			paidInvoice(t, store, "paid", 0, &refundTo)
+			var signed int
+			svc := service.NewRefund(store, store, store,
+				resolverFunc(func(ctx context.Context, args dpp.PaymailDestinationArgs) (*dpp.PaymailDestination, error) {
+					return &dpp.PaymailDestination{Outputs: test.outputs}, nil
+				}),
+				refundSignerFunc(func(ctx context.Context, outputs []dpp.Output) (*bt.Tx, error) {
+					signed++
+					return bt.NewTx(), nil
+				}),
+				broadcasterFunc(func(ctx context.Context, tx *bt.Tx) error {
+					return nil
+				}), nil, nil)
+			_, err := svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 500, Reason: "returned"})
+			is.True(err != nil)
+			is.Equal(err.Error(), test.expErr)
+			is.Equal(signed, 0)
+			refunds, err := store.Refunds(ctx, dpp.RefundArgs{PaymentID: "paid"})
+			is.NoErr(err)
+			is.Equal(len(refunds), 0)
+		})
+	}
+}
+
+func TestRefund_RefundCreate_BroadcastFailed(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	is.NoErr(err)
+	resolver := paymailtest.NewResolver()
+	resolver.Add("me@paymail.com", script)
+	refundTo := "me@paymail.com"
+	store := inmemory.NewStore()
+	paidInvoice(t, store, "paid", 0, &refundTo)
+
+	fail := true
+	svc := service.NewRefund(store, store, store, resolver,
+		refundSignerFunc(func(ctx context.Context, outputs []dpp.Output) (*bt.Tx, error) {
+			tx := bt.NewTx()
+			for _, o := range outputs {
+				tx.AddOutput(&bt.Output{Satoshis: o.Amount, LockingScript: o.LockingScript})
+			}
+			return tx, nil
+		}),
+		broadcasterFunc(func(ctx context.Context, tx *bt.Tx) error {
+			if fail {
+				return errors.New("rejected")
+			}
+			return nil
+		}), nil, nil)
+
+	_, err = svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 1000, Reason: "returned"})
+	is.True(err != nil)
+	refunds, err := store.Refunds(ctx, dpp.RefundArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(len(refunds), 1)
+	is.Equal(refunds[0].Status, dpp.RefundStatusFailed)
+	inv, err := store.Invoice(ctx, dpp.InvoiceArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(inv.State, dpp.InvoiceStateBroadcast)
+
+	// a failed refund does not count against the amount left to refund.
+	fail = false
+	rf, err := svc.RefundCreate(ctx, dpp.RefundArgs{PaymentID: "paid"}, dpp.RefundCreate{Amount: 1000, Reason: "returned"})
+	is.NoErr(err)
+	is.Equal(rf.Status, dpp.RefundStatusBroadcast)
+	refunds, err = store.Refunds(ctx, dpp.RefundArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(refunds[0].Status, dpp.RefundStatusFailed)
+	is.Equal(refunds[1].Status, dpp.RefundStatusBroadcast)
+	inv, err = store.Invoice(ctx, dpp.InvoiceArgs{PaymentID: "paid"})
+	is.NoErr(err)
+	is.Equal(inv.State, dpp.InvoiceStateRefunded)
+}
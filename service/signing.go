@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type merchantKey struct {
+	key *bec.PrivateKey
+}
+
+// NewMerchantKey will setup and return a MerchantKeyReader publishing the public key of key.
+func NewMerchantKey(key *bec.PrivateKey) dpp.MerchantKeyReader {
+	return &merchantKey{key: key}
+}
+
+// MerchantPublicKey returns the compressed public key hex encoded.
+func (m *merchantKey) MerchantPublicKey(ctx context.Context) (*dpp.MerchantPublicKey, error) {
+	return &dpp.MerchantPublicKey{PublicKey: hex.EncodeToString(m.key.PubKey().SerialiseCompressed())}, nil
+}
+
+type paymentRequestEnvelope struct {
+	prr dpp.PaymentRequestReader
+	key *bec.PrivateKey
+}
+
+// NewPaymentRequestEnvelope will setup and return a PaymentRequestEnvelopeReader that reads
+// PaymentRequests from prr and signs them with the merchant key.
+func NewPaymentRequestEnvelope(prr dpp.PaymentRequestReader, key *bec.PrivateKey) dpp.PaymentRequestEnvelopeReader {
+	return &paymentRequestEnvelope{prr: prr, key: key}
+}
+
+// PaymentRequestEnvelope will read the PaymentRequest and return it signed.
+func (p *paymentRequestEnvelope) PaymentRequestEnvelope(ctx context.Context, args dpp.PaymentRequestArgs) (*envelope.JSONEnvelope, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	pr, err := p.prr.PaymentRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	env, err := dpp.NewSignedEnvelope(p.key, pr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign payment request %s", args.PaymentID)
+	}
+	return env, nil
+}
+
+type paymentACKSigner struct {
+	svc dpp.PaymentService
+	key *bec.PrivateKey
+}
+
+// NewPaymentACKSigner will wrap a PaymentService and add a Receipt, the PaymentACK signed
+// by the merchant key, to the ACK of each accepted payment.
+func NewPaymentACKSigner(svc dpp.PaymentService, key *bec.PrivateKey) dpp.PaymentService {
+	return &paymentACKSigner{svc: svc, key: key}
+}
+
+// PaymentCreate will pass the payment to the wrapped service and sign the returned ACK.
+func (p *paymentACKSigner) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ack, err := p.svc.PaymentCreate(ctx, args, req)
+	if err != nil || ack == nil || ack.Error > 0 {
+		return ack, err
+	}
+	signed := *ack
+	signed.Receipt = nil
+	env, err := dpp.NewSignedEnvelope(p.key, signed)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to sign payment ack for %s", args.PaymentID)
+	}
+	signed.Receipt = env
+	return &signed, nil
+}
+
+type paymentRequestPublicKey struct {
+	prr dpp.PaymentRequestReader
+	pub string
+}
+
+// NewPaymentRequestPublicKey will wrap a PaymentRequestReader and publish the merchant public
+// key in each PaymentRequest so wallets can encrypt sensitive Payment fields to it.
+func NewPaymentRequestPublicKey(prr dpp.PaymentRequestReader, pub *bec.PublicKey) dpp.PaymentRequestReader {
+	return &paymentRequestPublicKey{prr: prr, pub: hex.EncodeToString(pub.SerialiseCompressed())}
+}
+
+// PaymentRequest will read the PaymentRequest and set its PublicKey.
+func (p *paymentRequestPublicKey) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	pr, err := p.prr.PaymentRequest(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	pr.PublicKey = p.pub
+	return pr, nil
+}
+
+type paymentDecrypter struct {
+	svc dpp.PaymentService
+	key *bec.PrivateKey
+}
+
+// NewPaymentDecrypter will wrap a PaymentService and decrypt encrypted payments with the
+// merchant key before they are passed on. It should wrap any decorators that validate payments.
+func NewPaymentDecrypter(svc dpp.PaymentService, key *bec.PrivateKey) dpp.PaymentService {
+	return &paymentDecrypter{svc: svc, key: key}
+}
+
+// PaymentCreate will decrypt the payment and pass it to the wrapped service.
+func (p *paymentDecrypter) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	if err := req.Decrypt(p.key); err != nil {
+		return nil, err
+	}
+	return p.svc.PaymentCreate(ctx, args, req)
+}
@@ -0,0 +1,138 @@
+package service_test
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/mocks"
+	"github.com/libsv/go-dpp/service"
+)
+
+func TestPaymentRequestEnvelope(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	store := inmemory.NewStore()
+	_, err = store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Network:     "mainnet",
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123"},
+	})
+	is.NoErr(err)
+
+	// the wallet fetches and pins the published key.
+	pub, err := service.NewMerchantKey(key).MerchantPublicKey(ctx)
+	is.NoErr(err)
+	pubBytes, err := hex.DecodeString(pub.PublicKey)
+	is.NoErr(err)
+	pubKey, err := bec.ParsePubKey(pubBytes, bec.S256())
+	is.NoErr(err)
+
+	env, err := service.NewPaymentRequestEnvelope(store, key).PaymentRequestEnvelope(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	pr, err := dpp.VerifyPaymentRequest(env, pubKey)
+	is.NoErr(err)
+	is.Equal(pr.Beneficiary.PaymentReference, "abc123")
+
+	_, err = service.NewPaymentRequestEnvelope(store, key).PaymentRequestEnvelope(ctx, dpp.PaymentRequestArgs{})
+	is.True(err != nil)
+}
+
+func TestPaymentACKSigner(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	other, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	reject := false
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			if reject {
+				return &dpp.PaymentACK{ID: args.PaymentID, Memo: "rejected", Error: 1}, nil
+			}
+			return &dpp.PaymentACK{ID: args.PaymentID, TxID: "abc", Memo: "thanks"}, nil
+		},
+	}
+	payment, _ := paymentFromTx(t, []uint32{0}, 1000)
+	signer := service.NewPaymentACKSigner(svc, key)
+
+	ack, err := signer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, payment)
+	is.NoErr(err)
+	is.True(ack.Receipt != nil)
+	signed, err := dpp.VerifyPaymentACK(*ack, key.PubKey())
+	is.NoErr(err)
+	is.Equal(signed.ID, "invoice1")
+	is.Equal(signed.TxID, "abc")
+	is.Equal(signed.Receipt, nil)
+
+	_, err = dpp.VerifyPaymentACK(*ack, other.PubKey())
+	is.True(err != nil)
+
+	// a receipt cannot be attached to an ack for another transaction.
+	swapped := *ack
+	swapped.TxID = "def"
+	_, err = dpp.VerifyPaymentACK(swapped, key.PubKey())
+	is.True(err != nil)
+
+	reject = true
+	ack, err = signer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, payment)
+	is.NoErr(err)
+	is.Equal(ack.Receipt, nil)
+	_, err = dpp.VerifyPaymentACK(*ack, key.PubKey())
+	is.True(err != nil)
+
+	// a missing ack is passed on rather than signed.
+	svc.PaymentCreateFunc = func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+		return nil, nil
+	}
+	ack, err = signer.PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "invoice1"}, payment)
+	is.NoErr(err)
+	is.True(ack == nil)
+}
+
+func TestPaymentDecrypter(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	store := inmemory.NewStore()
+	_, err = store.PaymentRequestCreate(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"}, dpp.PaymentRequest{
+		Network:     "mainnet",
+		Beneficiary: &dpp.Beneficiary{PaymentReference: "abc123"},
+	})
+	is.NoErr(err)
+
+	// the wallet reads the published key and encrypts to it.
+	pr, err := service.NewPaymentRequestPublicKey(store, key.PubKey()).PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	is.NoErr(err)
+	pub, err := pr.EncryptionKey()
+	is.NoErr(err)
+	req, _ := paymentFromTx(t, []uint32{0}, 1000)
+	req.Memo = "private memo"
+	is.NoErr(req.Encrypt(pub))
+
+	svc := &mocks.PaymentServiceMock{
+		PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+			return &dpp.PaymentACK{ID: args.PaymentID}, nil
+		},
+	}
+	_, err = service.NewPaymentDecrypter(svc, key).PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, req)
+	is.NoErr(err)
+	is.Equal(len(svc.PaymentCreateCalls()), 1)
+	got := svc.PaymentCreateCalls()[0].Req
+	is.Equal(got.Memo, "private memo")
+	is.Equal(got.Encrypted, "")
+
+	other, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	_, err = service.NewPaymentDecrypter(svc, other).PaymentCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"}, req)
+	is.True(err != nil)
+	is.Equal(len(svc.PaymentCreateCalls()), 1)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+type webhooks struct {
+	orw dpp.OutboxReaderWriter
+}
+
+// NewWebhooks will setup and return a new WebhookService used to list webhooks,
+// such as dead letters, and replay them.
+func NewWebhooks(orw dpp.OutboxReaderWriter) dpp.WebhookService {
+	return &webhooks{orw: orw}
+}
+
+// Webhooks will return the outbox messages matching the args.
+func (w *webhooks) Webhooks(ctx context.Context, args dpp.OutboxArgs) ([]dpp.OutboxMessage, error) {
+	mm, err := w.orw.OutboxMessages(ctx, args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read webhooks")
+	}
+	return mm, nil
+}
+
+// WebhookReplay will reset the message to pending with no attempts so the worker delivers it
+// on its next pass, delivered messages can be replayed as well as dead ones. An
+// OutboxConflictError is returned if the worker updates the message while it is being replayed.
+func (w *webhooks) WebhookReplay(ctx context.Context, args dpp.WebhookArgs) (*dpp.OutboxMessage, error) {
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	m, err := w.orw.OutboxMessage(ctx, args)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read webhook %s", args.ID)
+	}
+	now := time.Now().UTC()
+	m.Status = dpp.WebhookStatusPending
+	m.Attempts = 0
+	m.LastError = ""
+	m.NextAttemptAt = now
+	m.UpdatedAt = now
+	if err := w.orw.OutboxUpdate(ctx, *m); err != nil {
+		return nil, errors.Wrapf(err, "failed to replay webhook %s", args.ID)
+	}
+	m.Version++
+	return m, nil
+}
@@ -0,0 +1,141 @@
+package dpp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSignature is returned when a signed envelope fails verification.
+var ErrInvalidSignature = errors.New("envelope signature is invalid")
+
+// MerchantPublicKey is the hex encoded compressed public key the merchant signs with,
+// published so wallets can pin it and verify signed messages.
+type MerchantPublicKey struct {
+	PublicKey string `json:"publicKey" example:"0294d0ee9d4da2b6a1d8e12bfd4f4b2cc7c69fe4ee2cbdc02d3e3f0e4a5a0d3a5c"`
+}
+
+// MerchantKeyReader returns the merchant public key.
+type MerchantKeyReader interface {
+	MerchantPublicKey(ctx context.Context) (*MerchantPublicKey, error)
+}
+
+// PaymentRequestEnvelopeReader returns PaymentRequests signed by the merchant.
+type PaymentRequestEnvelopeReader interface {
+	// PaymentRequestEnvelope returns the PaymentRequest JSON as the payload of a signed envelope.
+	PaymentRequestEnvelope(ctx context.Context, args PaymentRequestArgs) (*envelope.JSONEnvelope, error)
+}
+
+// NewSignedEnvelope will encode the payload as JSON and sign it with the key.
+//
+// Unlike envelope.NewJSONEnvelope, which signs with a random key, the signature can
+// be attributed to the key holder. The payload is hashed with backslashes removed
+// to match envelope.JSONEnvelope.IsValid.
+func NewSignedEnvelope(key *bec.PrivateKey, payload interface{}) (*envelope.JSONEnvelope, error) {
+	bb, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode payload")
+	}
+	hash := sha256.Sum256([]byte(strings.ReplaceAll(string(bb), `\`, "")))
+	sig, err := key.Sign(hash[:])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign payload")
+	}
+	sigHex := hex.EncodeToString(sig.Serialise())
+	pubHex := hex.EncodeToString(key.PubKey().SerialiseCompressed())
+	return &envelope.JSONEnvelope{
+		Payload:   string(bb),
+		Signature: &sigHex,
+		PublicKey: &pubHex,
+		Encoding:  "UTF-8",
+		MimeType:  "application/json",
+	}, nil
+}
+
+// OpenSignedEnvelope will check the envelope is signed by pubKey and decode its payload into out.
+func OpenSignedEnvelope(env *envelope.JSONEnvelope, pubKey *bec.PublicKey, out interface{}) error {
+	if env == nil || env.Signature == nil || env.PublicKey == nil {
+		return errors.New("envelope is not signed")
+	}
+	if pubKey == nil {
+		return errors.New("a public key is required to verify the envelope")
+	}
+	if *env.PublicKey != hex.EncodeToString(pubKey.SerialiseCompressed()) {
+		return errors.New("envelope is not signed by the expected public key")
+	}
+	ok, err := env.IsValid()
+	if err != nil {
+		return errors.Wrap(err, "failed to verify envelope")
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return errors.Wrap(json.Unmarshal([]byte(env.Payload), out), "failed to decode envelope payload")
+}
+
+// VerifyPaymentRequest is used by wallets to check a PaymentRequest envelope was signed
+// by the merchant public key and return the PaymentRequest. The public key should be
+// pinned or obtained from a trusted source, not from the envelope itself.
+func VerifyPaymentRequest(env *envelope.JSONEnvelope, pubKey *bec.PublicKey) (*PaymentRequest, error) {
+	var pr PaymentRequest
+	if err := OpenSignedEnvelope(env, pubKey, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// VerifyPaymentACK is used by wallets to check the Receipt of a PaymentACK was signed by the
+// merchant public key for the same payment and transaction. The signed PaymentACK is returned.
+func VerifyPaymentACK(ack PaymentACK, pubKey *bec.PublicKey) (*PaymentACK, error) {
+	if ack.Receipt == nil {
+		return nil, errors.New("payment ack has no receipt")
+	}
+	var signed PaymentACK
+	if err := OpenSignedEnvelope(ack.Receipt, pubKey, &signed); err != nil {
+		return nil, err
+	}
+	if signed.ID != ack.ID || signed.TxID != ack.TxID || signed.Error != ack.Error {
+		return nil, errors.New("payment ack receipt does not match the payment ack")
+	}
+	return &signed, nil
+}
+
+// MerchantDataSecretMinLength is the minimum length in bytes of the secret used to tag MerchantData.
+const MerchantDataSecretMinLength = 32
+
+// ValidateMerchantDataSecret will ensure a MerchantData secret is long enough that tags cannot be forged.
+func ValidateMerchantDataSecret(secret []byte) error {
+	if len(secret) < MerchantDataSecretMinLength {
+		return errors.Errorf("merchant data secret must be at least %d bytes", MerchantDataSecretMinLength)
+	}
+	return nil
+}
+
+// merchantDataTagPrefix domain separates MerchantData tags from other uses of the secret.
+const merchantDataTagPrefix = "dpp/merchantData/v1:"
+
+// MerchantDataTag returns the hex encoded HMAC-SHA256 of the beneficiary PaymentReference
+// using a secret known only to the payment host.
+func MerchantDataTag(secret []byte, b Beneficiary) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write([]byte(merchantDataTagPrefix + b.Reference()))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyMerchantDataTag will check the AuthTag of the beneficiary was issued with secret
+// for its PaymentReference.
+func VerifyMerchantDataTag(secret []byte, b Beneficiary) bool {
+	tag, err := hex.DecodeString(b.AuthTag)
+	if err != nil || b.AuthTag == "" {
+		return false
+	}
+	expected, _ := hex.DecodeString(MerchantDataTag(secret, b))
+	return hmac.Equal(tag, expected)
+}
@@ -0,0 +1,59 @@
+package dpp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/libsv/go-bk/bec"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/matryer/is"
+
+	"github.com/libsv/go-dpp"
+)
+
+func TestVerifyPaymentRequest(t *testing.T) {
+	is := is.New(t)
+	key, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	other, err := bec.NewPrivateKey(bec.S256())
+	is.NoErr(err)
+	script, err := bscript.NewFromHexString(p2pkh)
+	is.NoErr(err)
+	pr := dpp.PaymentRequest{
+		Network:      "mainnet",
+		Destinations: dpp.PaymentDestinations{Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}}},
+		Memo:         "<invoice> & co",
+		Beneficiary:  &dpp.Beneficiary{Name: "merchant", PaymentReference: "abc123"},
+	}
+	env, err := dpp.NewSignedEnvelope(key, pr)
+	is.NoErr(err)
+
+	verified, err := dpp.VerifyPaymentRequest(env, key.PubKey())
+	is.NoErr(err)
+	is.Equal(verified.Memo, pr.Memo)
+	is.Equal(verified.Beneficiary.PaymentReference, "abc123")
+	is.True(verified.Destinations.Outputs[0].LockingScript.Equals(script))
+
+	// signed by another key.
+	_, err = dpp.VerifyPaymentRequest(env, other.PubKey())
+	is.True(err != nil)
+
+	// destinations swapped in transit.
+	tampered := *env
+	tampered.Payload = strings.Replace(env.Payload, `"amount":1000`, `"amount":1`, 1)
+	is.True(tampered.Payload != env.Payload)
+	_, err = dpp.VerifyPaymentRequest(&tampered, key.PubKey())
+	is.Equal(err, dpp.ErrInvalidSignature)
+
+	// the attacker re-signs with their own key.
+	forged, err := dpp.NewSignedEnvelope(other, pr)
+	is.NoErr(err)
+	_, err = dpp.VerifyPaymentRequest(forged, key.PubKey())
+	is.True(err != nil)
+
+	// unsigned envelopes are rejected.
+	unsigned := *env
+	unsigned.Signature, unsigned.PublicKey = nil, nil
+	_, err = dpp.VerifyPaymentRequest(&unsigned, key.PubKey())
+	is.True(err != nil)
+}
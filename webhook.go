@@ -0,0 +1,203 @@
+package dpp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Webhook request headers.
+const (
+	// WebhookHeaderID contains the OutboxMessage ID, it is the same for every attempt
+	// so receivers can ignore duplicate deliveries.
+	WebhookHeaderID = "X-DPP-Webhook-Id"
+	// WebhookHeaderEvent contains the EventType.
+	WebhookHeaderEvent = "X-DPP-Webhook-Event"
+	// WebhookHeaderSignature contains the WebhookSignature of the request body.
+	WebhookHeaderSignature = "X-DPP-Webhook-Signature"
+)
+
+// WebhookStatus describes where an outbox message is in its delivery lifecycle.
+type WebhookStatus string
+
+// Supported webhook statuses.
+const (
+	// WebhookStatusPending means the message is waiting to be delivered or retried.
+	WebhookStatusPending WebhookStatus = "pending"
+	// WebhookStatusDelivered means the receiver acknowledged the message with a 2xx response.
+	WebhookStatusDelivered WebhookStatus = "delivered"
+	// WebhookStatusDead means every attempt failed, the message can be replayed.
+	WebhookStatusDead WebhookStatus = "dead"
+)
+
+// OutboxMessage is a webhook waiting to be, or that has been, delivered.
+type OutboxMessage struct {
+	// ID is unique to the event and is sent as WebhookHeaderID.
+	ID        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	PaymentID string          `json:"paymentId"`
+	Payload   json.RawMessage `json:"payload" swaggertype:"object"`
+	Status    WebhookStatus   `json:"status" enums:"pending,delivered,dead"`
+	// Attempts is the number of failed or successful delivery attempts.
+	Attempts int `json:"attempts"`
+	// NextAttemptAt is the earliest time a pending message will be delivered.
+	NextAttemptAt time.Time `json:"nextAttemptAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	LastError     string    `json:"lastError,omitempty"`
+	// Version is incremented by the store each time the message is updated, OutboxUpdate
+	// only succeeds if it has not changed since the message was read.
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+	UpdatedAt time.Time `json:"updatedAt" swaggertype:"primitive,string" example:"2019-10-12T07:20:50.52Z"`
+}
+
+// NewOutboxMessage returns a pending message delivering the event, id should be unique.
+func NewOutboxMessage(id, paymentID string, e Event, at time.Time) (OutboxMessage, error) {
+	bb, err := json.Marshal(e)
+	if err != nil {
+		return OutboxMessage{}, errors.Wrapf(err, "failed to encode %s webhook", e.EventType())
+	}
+	return OutboxMessage{
+		ID:            id,
+		Type:          e.EventType(),
+		PaymentID:     paymentID,
+		Payload:       bb,
+		Status:        WebhookStatusPending,
+		NextAttemptAt: at,
+		CreatedAt:     at,
+		UpdatedAt:     at,
+	}, nil
+}
+
+// Body returns the webhook request body, an envelope containing the message id, type and event.
+func (o OutboxMessage) Body() ([]byte, error) {
+	bb, err := json.Marshal(struct {
+		ID        string          `json:"id"`
+		Type      EventType       `json:"type"`
+		CreatedAt time.Time       `json:"createdAt"`
+		Data      json.RawMessage `json:"data"`
+	}{ID: o.ID, Type: o.Type, CreatedAt: o.CreatedAt, Data: o.Payload})
+	return bb, errors.Wrap(err, "failed to encode webhook body")
+}
+
+// WebhookSignature returns the WebhookHeaderSignature value for the body sent at time t, in the
+// format t=<unix seconds>,v1=<hex hmac-sha256 of "<unix seconds>.<body>">.
+func WebhookSignature(secret []byte, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", ts, webhookMAC(secret, ts, body))
+}
+
+// VerifyWebhookSignature checks a WebhookHeaderSignature value for the body, it is used by receivers.
+// Signatures made more than tolerance from now are rejected to prevent replays.
+func VerifyWebhookSignature(secret []byte, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "t":
+			ts = kv[1]
+		case "v1":
+			sig = kv[1]
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || sig == "" {
+		return errors.New("malformed webhook signature")
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return errors.New("webhook signature timestamp is outside the tolerance")
+	}
+	if !hmac.Equal([]byte(sig), []byte(webhookMAC(secret, ts, body))) {
+		return errors.New("webhook signature does not match")
+	}
+	return nil
+}
+
+func webhookMAC(secret []byte, ts string, body []byte) string {
+	h := hmac.New(sha256.New, secret)
+	_, _ = h.Write([]byte(ts + "."))
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ErrOutboxMessageNotFound is returned when an outbox message does not exist.
+var ErrOutboxMessageNotFound = errors.New("outbox message not found")
+
+// OutboxConflictError is returned by OutboxUpdate when the message was updated after it was
+// read, ie replayed while the worker was delivering it.
+type OutboxConflictError struct {
+	ID string
+}
+
+// Error satisfies the error interface.
+func (e OutboxConflictError) Error() string {
+	return fmt.Sprintf("webhook %s was updated after it was read", e.ID)
+}
+
+// Conflict indicates the update conflicts with the stored message.
+func (e OutboxConflictError) Conflict() bool {
+	return true
+}
+
+// OutboxArgs are used to filter outbox messages.
+type OutboxArgs struct {
+	// Status returns messages in this status.
+	Status WebhookStatus `query:"status"`
+	// DueBy returns pending messages whose NextAttemptAt is at or before this time.
+	DueBy time.Time `query:"-"`
+	// Limit is the maximum number of messages to return, 0 returns all.
+	Limit int `query:"limit"`
+}
+
+// WebhookArgs identifies a single outbox message.
+type WebhookArgs struct {
+	ID string `param:"webhookID"`
+}
+
+// Validate will ensure that the WebhookArgs are supplied and correct.
+func (w WebhookArgs) Validate() error {
+	return validator.New().
+		Validate("webhookID", validator.NotEmpty(w.ID)).
+		Err()
+}
+
+// OutboxReader reads outbox messages.
+type OutboxReader interface {
+	// OutboxMessage returns a message by id or ErrOutboxMessageNotFound.
+	OutboxMessage(ctx context.Context, args WebhookArgs) (*OutboxMessage, error)
+	// OutboxMessages returns the messages matching the args, oldest first.
+	OutboxMessages(ctx context.Context, args OutboxArgs) ([]OutboxMessage, error)
+}
+
+// OutboxWriter updates outbox messages, messages are created with the change they
+// describe using InvoiceUpdate.Outbox, PaymentCreateArgs.Outbox or ProofCreateArgs.Outbox.
+type OutboxWriter interface {
+	// OutboxUpdate replaces the stored message with the same id and increments its Version. If the
+	// stored Version does not match req.Version an OutboxConflictError is returned and it is unchanged.
+	OutboxUpdate(ctx context.Context, req OutboxMessage) error
+}
+
+// OutboxReaderWriter combines the reader and writer interfaces.
+type OutboxReaderWriter interface {
+	OutboxReader
+	OutboxWriter
+}
+
+// WebhookService lets merchants inspect webhooks and replay dead ones.
+type WebhookService interface {
+	// Webhooks returns the outbox messages matching the args, ie the dead letters.
+	Webhooks(ctx context.Context, args OutboxArgs) ([]OutboxMessage, error)
+	// WebhookReplay will queue the message to be delivered again immediately.
+	WebhookReplay(ctx context.Context, args WebhookArgs) (*OutboxMessage, error)
+}
@@ -0,0 +1,84 @@
+package dpp
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"id":"abc123"}`)
+	now := time.Unix(1600000000, 0)
+	tests := map[string]struct {
+		secret []byte
+		header string
+		body   []byte
+		exp    string
+	}{
+		"valid signature should pass": {
+			secret: secret,
+			header: WebhookSignature(secret, now, body),
+			body:   body,
+		}, "signature within tolerance should pass": {
+			secret: secret,
+			header: WebhookSignature(secret, now.Add(-4*time.Minute), body),
+			body:   body,
+		}, "old signature should error": {
+			secret: secret,
+			header: WebhookSignature(secret, now.Add(-6*time.Minute), body),
+			body:   body,
+			exp:    "webhook signature timestamp is outside the tolerance",
+		}, "changed body should error": {
+			secret: secret,
+			header: WebhookSignature(secret, now, body),
+			body:   []byte(`{"id":"def456"}`),
+			exp:    "webhook signature does not match",
+		}, "wrong secret should error": {
+			secret: []byte("other"),
+			header: WebhookSignature(secret, now, body),
+			body:   body,
+			exp:    "webhook signature does not match",
+		}, "malformed header should error": {
+			secret: secret,
+			header: "v1=abc",
+			body:   body,
+			exp:    "malformed webhook signature",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			is := is.NewRelaxed(t)
+			err := VerifyWebhookSignature(test.secret, test.header, test.body, 5*time.Minute, now)
+			if test.exp == "" {
+				is.NoErr(err)
+				return
+			}
+			is.True(err != nil)
+			is.Equal(test.exp, err.Error())
+		})
+	}
+}
+
+func TestOutboxMessage_Body(t *testing.T) {
+	is := is.New(t)
+	at := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	m, err := NewOutboxMessage("msg1", "abc123", TransactionBroadcast{PaymentID: "abc123", TxID: "tx1", Timestamp: at}, at)
+	is.NoErr(err)
+	is.Equal(m.Status, WebhookStatusPending)
+	is.Equal(m.Type, EventTransactionBroadcast)
+	is.Equal(m.NextAttemptAt, at)
+	bb, err := m.Body()
+	is.NoErr(err)
+	var body struct {
+		ID   string               `json:"id"`
+		Type EventType            `json:"type"`
+		Data TransactionBroadcast `json:"data"`
+	}
+	is.NoErr(json.Unmarshal(bb, &body))
+	is.Equal(body.ID, "msg1")
+	is.Equal(body.Type, EventTransactionBroadcast)
+	is.Equal(body.Data.TxID, "tx1")
+}
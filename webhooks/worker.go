@@ -0,0 +1,191 @@
+// Package webhooks contains a worker that delivers the webhooks queued in a dpp.OutboxReaderWriter
+// to a merchant endpoint, signing each request with dpp.WebhookSignature.
+//
+// Delivery is at least once, a message can be sent again if the worker stops between
+// sending it and recording the result, so receivers should ignore repeated dpp.WebhookHeaderID values.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+)
+
+// Defaults applied to zero Config values.
+const (
+	DefaultMaxAttempts  = 10
+	DefaultBaseDelay    = 5 * time.Second
+	DefaultMaxDelay     = time.Hour
+	DefaultBatchSize    = 50
+	DefaultPollInterval = 5 * time.Second
+	DefaultTimeout      = 10 * time.Second
+)
+
+// Config configures a Worker.
+type Config struct {
+	// URL is the merchant endpoint webhooks are POSTed to.
+	URL string
+	// Secret signs each request, the merchant verifies it with dpp.VerifyWebhookSignature.
+	Secret []byte
+	// MaxAttempts is the number of failed attempts before a message is dead.
+	MaxAttempts int
+	// BaseDelay is the delay after the first failed attempt, it doubles for each
+	// further attempt up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// BatchSize is the maximum number of messages delivered by each pass.
+	BatchSize int
+	// PollInterval is the time Run waits between passes.
+	PollInterval time.Duration
+	// Timeout is the timeout of each request.
+	Timeout time.Duration
+	// OnError is called with errors returned by a pass of Run, which continues on the next
+	// tick, by default they are logged.
+	OnError func(err error)
+}
+
+// Worker delivers pending outbox messages.
+type Worker struct {
+	cfg Config
+	orw dpp.OutboxReaderWriter
+	c   *http.Client
+	now func() time.Time
+}
+
+// NewWorker will setup and return a new Worker delivering messages from orw.
+func NewWorker(cfg Config, orw dpp.OutboxReaderWriter) *Worker {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = DefaultBaseDelay
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = DefaultMaxDelay
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.OnError == nil {
+		cfg.OnError = func(err error) {
+			log.Printf("webhooks: %s", err)
+		}
+	}
+	return &Worker{
+		cfg: cfg,
+		orw: orw,
+		c:   &http.Client{Timeout: cfg.Timeout},
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run will deliver due messages every PollInterval until the context is cancelled. Errors,
+// ie the store being unavailable, are passed to OnError and the pass is retried on the next tick.
+func (w *Worker) Run(ctx context.Context) error {
+	t := time.NewTicker(w.cfg.PollInterval)
+	defer t.Stop()
+	for {
+		if _, err := w.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			w.cfg.OnError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// DeliverDue will attempt every pending message that is due, up to BatchSize, in the order
+// they were created and return the number delivered. Failed deliveries are rescheduled or,
+// once MaxAttempts is reached, marked as dead. The result is not recorded for a message that
+// was replayed during delivery, so the replay is kept.
+func (w *Worker) DeliverDue(ctx context.Context) (int, error) {
+	mm, err := w.orw.OutboxMessages(ctx, dpp.OutboxArgs{DueBy: w.now(), Limit: w.cfg.BatchSize})
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read due webhooks")
+	}
+	var delivered int
+	for _, m := range mm {
+		if ctx.Err() != nil {
+			return delivered, nil
+		}
+		err := w.send(ctx, m)
+		now := w.now()
+		m.Attempts++
+		m.UpdatedAt = now
+		switch {
+		case err == nil:
+			m.Status = dpp.WebhookStatusDelivered
+			m.LastError = ""
+			delivered++
+		case m.Attempts >= w.cfg.MaxAttempts:
+			m.Status = dpp.WebhookStatusDead
+			m.LastError = err.Error()
+		default:
+			m.LastError = err.Error()
+			m.NextAttemptAt = now.Add(w.backoff(m.Attempts))
+		}
+		if err := w.orw.OutboxUpdate(ctx, m); err != nil {
+			var errConflict dpp.OutboxConflictError
+			if errors.As(err, &errConflict) {
+				continue
+			}
+			return delivered, errors.Wrapf(err, "failed to update webhook %s", m.ID)
+		}
+	}
+	return delivered, nil
+}
+
+// backoff returns the delay before the next attempt after the given number of attempts.
+func (w *Worker) backoff(attempts int) time.Duration {
+	d := w.cfg.BaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= w.cfg.MaxDelay {
+			return w.cfg.MaxDelay
+		}
+	}
+	return d
+}
+
+// send will POST the message, any response other than a 2xx is an error.
+func (w *Worker) send(ctx context.Context, m dpp.OutboxMessage) error {
+	body, err := m.Body()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(dpp.WebhookHeaderID, m.ID)
+	req.Header.Set(dpp.WebhookHeaderEvent, string(m.Type))
+	req.Header.Set(dpp.WebhookHeaderSignature, dpp.WebhookSignature(w.cfg.Secret, w.now(), body))
+	resp, err := w.c.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to send webhook")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint returned %d", resp.StatusCode)
+	}
+	return nil
+}
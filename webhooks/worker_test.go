@@ -0,0 +1,220 @@
+package webhooks_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+	"github.com/pkg/errors"
+
+	"github.com/libsv/go-dpp"
+	"github.com/libsv/go-dpp/data/inmemory"
+	"github.com/libsv/go-dpp/service"
+	"github.com/libsv/go-dpp/webhooks"
+)
+
+type receiver struct {
+	mu    sync.Mutex
+	fails int
+	ids   []string
+	err   error
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	body, _ := ioutil.ReadAll(req.Body)
+	if err := dpp.VerifyWebhookSignature([]byte("secret"), req.Header.Get(dpp.WebhookHeaderSignature),
+		body, time.Minute, time.Now()); err != nil {
+		r.err = err
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.fails > 0 {
+		r.fails--
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	r.ids = append(r.ids, req.Header.Get(dpp.WebhookHeaderID))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setup returns a store with one queued webhook for a broadcast invoice.
+func setup(t *testing.T) (*inmemory.Store, string) {
+	is := is.New(t)
+	ctx := context.Background()
+	store := inmemory.NewStore()
+	_, err := store.InvoiceCreate(ctx, *dpp.NewInvoice("abc123", time.Now().UTC(), time.Time{}))
+	is.NoErr(err)
+	_, err = store.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: "abc123"}, dpp.InvoiceUpdate{
+		State: dpp.InvoiceStatePending, Timestamp: time.Now().UTC(),
+	})
+	is.NoErr(err)
+	m, err := dpp.NewOutboxMessage("msg1", "abc123", dpp.PaymentRejected{PaymentID: "abc123"}, time.Now().UTC())
+	is.NoErr(err)
+	_, err = store.InvoiceUpdate(ctx, dpp.InvoiceArgs{PaymentID: "abc123"}, dpp.InvoiceUpdate{
+		State: dpp.InvoiceStateCreated, Timestamp: time.Now().UTC(), Outbox: []dpp.OutboxMessage{m},
+	})
+	is.NoErr(err)
+	return store, m.ID
+}
+
+func TestWorker_DeliverDue(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store, id := setup(t)
+	r := &receiver{fails: 2}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	w := webhooks.NewWorker(webhooks.Config{URL: srv.URL, Secret: []byte("secret"), BaseDelay: time.Millisecond}, store)
+
+	for i := 0; i < 2; i++ {
+		n, err := w.DeliverDue(ctx)
+		is.NoErr(err)
+		is.Equal(n, 0)
+		m, err := store.OutboxMessage(ctx, dpp.WebhookArgs{ID: id})
+		is.NoErr(err)
+		is.Equal(m.Status, dpp.WebhookStatusPending)
+		is.Equal(m.Attempts, i+1)
+		is.Equal(m.LastError, "webhook endpoint returned 500")
+		is.True(m.NextAttemptAt.After(m.CreatedAt))
+		time.Sleep(5 * time.Millisecond)
+	}
+	n, err := w.DeliverDue(ctx)
+	is.NoErr(err)
+	is.Equal(n, 1)
+	is.NoErr(r.err)
+	is.Equal(r.ids, []string{id})
+	m, err := store.OutboxMessage(ctx, dpp.WebhookArgs{ID: id})
+	is.NoErr(err)
+	is.Equal(m.Status, dpp.WebhookStatusDelivered)
+	is.Equal(m.Attempts, 3)
+	is.Equal(m.LastError, "")
+
+	// delivered messages are not sent again.
+	n, err = w.DeliverDue(ctx)
+	is.NoErr(err)
+	is.Equal(n, 0)
+	is.Equal(len(r.ids), 1)
+}
+
+func TestWorker_DeadLetterReplay(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store, id := setup(t)
+	r := &receiver{fails: 2}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	w := webhooks.NewWorker(webhooks.Config{
+		URL: srv.URL, Secret: []byte("secret"), MaxAttempts: 2, BaseDelay: time.Millisecond,
+	}, store)
+
+	for i := 0; i < 2; i++ {
+		_, err := w.DeliverDue(ctx)
+		is.NoErr(err)
+		time.Sleep(5 * time.Millisecond)
+	}
+	svc := service.NewWebhooks(store)
+	dead, err := svc.Webhooks(ctx, dpp.OutboxArgs{Status: dpp.WebhookStatusDead})
+	is.NoErr(err)
+	is.Equal(len(dead), 1)
+	is.Equal(dead[0].ID, id)
+	is.Equal(dead[0].Attempts, 2)
+
+	// dead messages are not retried until they are replayed.
+	n, err := w.DeliverDue(ctx)
+	is.NoErr(err)
+	is.Equal(n, 0)
+
+	m, err := svc.WebhookReplay(ctx, dpp.WebhookArgs{ID: id})
+	is.NoErr(err)
+	is.Equal(m.Status, dpp.WebhookStatusPending)
+	is.Equal(m.Attempts, 0)
+	n, err = w.DeliverDue(ctx)
+	is.NoErr(err)
+	is.Equal(n, 1)
+	is.Equal(r.ids, []string{id})
+
+	_, err = svc.WebhookReplay(ctx, dpp.WebhookArgs{ID: "missing"})
+	is.True(err != nil)
+	is.Equal(err.Error(), "failed to read webhook missing: outbox message not found")
+}
+
+func TestWorker_ReplayDuringDelivery(t *testing.T) {
+	is := is.New(t)
+	ctx := context.Background()
+	store, id := setup(t)
+	svc := service.NewWebhooks(store)
+	// the message is replayed while the worker is waiting on the receiver.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, err := svc.WebhookReplay(ctx, dpp.WebhookArgs{ID: id})
+		is.NoErr(err)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	w := webhooks.NewWorker(webhooks.Config{URL: srv.URL, Secret: []byte("secret")}, store)
+
+	n, err := w.DeliverDue(ctx)
+	is.NoErr(err)
+	is.Equal(n, 1)
+	m, err := store.OutboxMessage(ctx, dpp.WebhookArgs{ID: id})
+	is.NoErr(err)
+	is.Equal(m.Status, dpp.WebhookStatusPending)
+	is.Equal(m.Attempts, 0)
+}
+
+// failingStore fails to read messages the first time it is called.
+type failingStore struct {
+	*inmemory.Store
+	mu    sync.Mutex
+	calls int
+}
+
+func (f *failingStore) OutboxMessages(ctx context.Context, args dpp.OutboxArgs) ([]dpp.OutboxMessage, error) {
+	f.mu.Lock()
+	f.calls++
+	calls := f.calls
+	f.mu.Unlock()
+	if calls == 1 {
+		return nil, errors.New("store unavailable")
+	}
+	return f.Store.OutboxMessages(ctx, args)
+}
+
+func TestWorker_RunContinuesAfterError(t *testing.T) {
+	is := is.New(t)
+	store, id := setup(t)
+	r := &receiver{}
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	w := webhooks.NewWorker(webhooks.Config{
+		URL: srv.URL, Secret: []byte("secret"), PollInterval: time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	}, &failingStore{Store: store})
+
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	is.Equal((<-errs).Error(), "failed to read due webhooks: store unavailable")
+	for {
+		m, err := store.OutboxMessage(context.Background(), dpp.WebhookArgs{ID: id})
+		is.NoErr(err)
+		if m.Status == dpp.WebhookStatusDelivered {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	is.NoErr(<-done)
+}